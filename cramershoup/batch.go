@@ -0,0 +1,137 @@
+package cramershoup
+
+import (
+	"crypto/rand"
+	"runtime"
+	"sync"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+type batchEntry struct {
+	csm   *CSMessage
+	alpha curve.Scalar
+}
+
+// DecryptBatch decrypts many messages to the same secret key, using one
+// worker per CPU. It returns the plaintexts and errors in the same order as
+// the given messages.
+func (cs *CramerShoup) DecryptBatch(sec *SecretKey, csms []*CSMessage) ([][]byte, []error) {
+	return cs.DecryptBatchWithWorkers(sec, csms, runtime.NumCPU())
+}
+
+// DecryptBatchWithWorkers decrypts many messages to the same secret key,
+// splitting them between the given number of goroutines. It returns the
+// plaintexts and errors in the same order as the given messages.
+//
+// Each worker checks the validity of all its messages at once: with random
+// blinding scalars ri it checks
+//
+//	sum(Vi*ri) = x1*sum(U1i*ri) + y1*sum(U1i*(ri*αi)) + x2*sum(U2i*ri) + y2*sum(U2i*(ri*αi))
+//
+// The sums are multi-scalar multiplications whose scalars are only the
+// random ri and the public αi, so they may run in variable time. The secret
+// key only multiplies the four sums, with the same multiplications Decrypt
+// uses. Only if that check fails are the messages checked one by one, so a
+// batch holding an invalid message takes longer. The time taken reveals
+// whether a batch held an invalid message, though not which one; call Decrypt
+// for each message if that must stay hidden.
+//
+// Messages are expected to have been decoded with DecodeMessage, which
+// ensures their points are in the prime order group. If the curve is not a
+// curve.MultiScalarMultiplier every message is checked on its own.
+func (cs *CramerShoup) DecryptBatchWithWorkers(sec *SecretKey, csms []*CSMessage, workers int) ([][]byte, []error) {
+	if workers < 1 {
+		workers = 1
+	}
+	size := (len(csms) + workers - 1) / workers
+
+	msgs := make([][]byte, len(csms))
+	errs := make([]error, len(csms))
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(csms); start += size {
+		end := start + size
+		if end > len(csms) {
+			end = len(csms)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			cs.decryptChunk(sec, csms[start:end], msgs[start:end], errs[start:end])
+		}(start, end)
+	}
+	wg.Wait()
+
+	return msgs, errs
+}
+
+func (cs *CramerShoup) decryptChunk(sec *SecretKey, csms []*CSMessage, msgs [][]byte, errs []error) {
+	if !isValidSecretKey(sec) {
+		for i := range errs {
+			errs[i] = ErrInvalidKey
+		}
+		return
+	}
+
+	entries := make([]*batchEntry, 0, len(csms))
+	indexes := make([]int, 0, len(csms))
+	for i, csm := range csms {
+		if err := cs.ValidateMessage(csm); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		// alpha = H(u1,u2,e)
+		entries = append(entries, &batchEntry{
+			csm:   csm,
+			alpha: cs.Curve.HashToScalar(csm.U1, csm.U2, csm.E),
+		})
+		indexes = append(indexes, i)
+	}
+
+	msm, ok := cs.Curve.(curve.MultiScalarMultiplier)
+	batchValid := ok && len(entries) > 1 && cs.batchIsValid(msm, sec, entries)
+	for j, entry := range entries {
+		i := indexes[j]
+		valid := batchValid || cs.Curve.EqualPoints(cs.expectedV(sec, entry.csm, entry.alpha), entry.csm.V)
+
+		// m = e - u1*z, m != 0
+		m := cs.Curve.SubPoints(entry.csm.E, cs.Curve.PointScalarMul(entry.csm.U1, sec.Z))
+		zero := cs.Curve.EqualPoints(m, cs.Curve.Identity())
+
+		if !valid || zero {
+			errs[i] = ErrInvalidCiphertext
+			continue
+		}
+		msgs[i] = m.Encode()
+	}
+}
+
+func (cs *CramerShoup) batchIsValid(msm curve.MultiScalarMultiplier, sec *SecretKey, entries []*batchEntry) bool {
+	u1s := make([]curve.Point, 0, len(entries))
+	u2s := make([]curve.Point, 0, len(entries))
+	vs := make([]curve.Point, 0, len(entries))
+	rs := make([]curve.Scalar, 0, len(entries))
+	blinded := make([]curve.Scalar, 0, len(entries))
+
+	for _, entry := range entries {
+		r, err := cs.Curve.RandScalar(rand.Reader)
+		if err != nil {
+			return false
+		}
+		u1s = append(u1s, entry.csm.U1)
+		u2s = append(u2s, entry.csm.U2)
+		vs = append(vs, entry.csm.V)
+		rs = append(rs, r)
+		blinded = append(blinded, cs.Curve.Mul(r, entry.alpha))
+	}
+
+	// a = x1*sum(u1*r) + x2*sum(u2*r)
+	a := cs.Curve.PointDoubleScalarMul(msm.MultiScalarMul(u1s, rs), sec.X1, msm.MultiScalarMul(u2s, rs), sec.X2)
+
+	// b = y1*sum(u1*(r*alpha)) + y2*sum(u2*(r*alpha))
+	b := cs.Curve.PointDoubleScalarMul(msm.MultiScalarMul(u1s, blinded), sec.Y1, msm.MultiScalarMul(u2s, blinded), sec.Y2)
+
+	return cs.Curve.EqualPoints(cs.Curve.AddPoints(a, b), msm.MultiScalarMul(vs, rs))
+}
@@ -0,0 +1,163 @@
+package cramershoup
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+func encryptMany(c *C, pub *PublicKey, n int) []*CSMessage {
+	csms := make([]*CSMessage, n)
+	for i := range csms {
+		csm, err := cs.Encrypt(message, rand.Reader, pub)
+		c.Assert(err, IsNil)
+		csms[i] = csm
+	}
+	return csms
+}
+
+func (s *CSSuite) Test_DecryptBatch(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 5)
+
+	for _, workers := range []int{0, 1, 2, 5, 8} {
+		msgs, errs := cs.DecryptBatchWithWorkers(keyPair.Sec, csms, workers)
+
+		c.Assert(msgs, HasLen, len(csms))
+		c.Assert(errs, HasLen, len(csms))
+		for i := range csms {
+			c.Assert(errs[i], IsNil)
+			c.Assert(msgs[i], DeepEquals, message)
+		}
+	}
+
+	msgs, errs := cs.DecryptBatch(keyPair.Sec, csms)
+	for i := range csms {
+		c.Assert(errs[i], IsNil)
+		c.Assert(msgs[i], DeepEquals, message)
+	}
+
+	msgs, errs = cs.DecryptBatch(keyPair.Sec, nil)
+	c.Assert(msgs, HasLen, 0)
+	c.Assert(errs, HasLen, 0)
+}
+
+func (s *CSSuite) Test_DecryptBatchReportsInvalidMessages(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	otherKeyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 6)
+
+	tampered := *csms[1]
+	tampered.V = cs.Curve.AddPoints(tampered.V, cs.Curve.G())
+	csms[1] = &tampered
+	csms[3] = encryptMany(c, otherKeyPair.Pub, 1)[0]
+	malformed := *csms[4]
+	malformed.U1 = cs.Curve.Identity()
+	csms[4] = &malformed
+	csms[5] = nil
+
+	for _, workers := range []int{1, 2, 6} {
+		msgs, errs := cs.DecryptBatchWithWorkers(keyPair.Sec, csms, workers)
+
+		c.Assert(errs[0], IsNil)
+		c.Assert(msgs[0], DeepEquals, message)
+		c.Assert(errs[1], Equals, ErrInvalidCiphertext)
+		c.Assert(msgs[1], IsNil)
+		c.Assert(errs[2], IsNil)
+		c.Assert(msgs[2], DeepEquals, message)
+		c.Assert(errs[3], Equals, ErrInvalidCiphertext)
+		c.Assert(msgs[3], IsNil)
+		c.Assert(errs[4], Equals, ErrMalformedPoint)
+		c.Assert(msgs[4], IsNil)
+		c.Assert(errs[5], Equals, ErrMalformedPoint)
+		c.Assert(msgs[5], IsNil)
+	}
+}
+
+func (s *CSSuite) Benchmark_DecryptLoop(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 64)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		for _, csm := range csms {
+			cs.Decrypt(keyPair.Sec, csm)
+		}
+	}
+}
+
+func (s *CSSuite) Benchmark_DecryptBatch(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 64)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		cs.DecryptBatch(keyPair.Sec, csms)
+	}
+}
+
+func (s *CSSuite) Benchmark_DecryptBatchSingleWorker(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 64)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		cs.DecryptBatchWithWorkers(keyPair.Sec, csms, 1)
+	}
+}
+
+func (s *CSSuite) Test_BatchValidity(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 3)
+
+	entries := make([]*batchEntry, len(csms))
+	for i, csm := range csms {
+		entries[i] = &batchEntry{
+			csm:   csm,
+			alpha: cs.Curve.HashToScalar(csm.U1, csm.U2, csm.E),
+		}
+	}
+	msm := cs.Curve.(curve.MultiScalarMultiplier)
+
+	c.Assert(cs.batchIsValid(msm, keyPair.Sec, entries), Equals, true)
+
+	tampered := *csms[2]
+	tampered.V = cs.Curve.AddPoints(tampered.V, cs.Curve.G())
+	entries[2].csm = &tampered
+
+	c.Assert(cs.batchIsValid(msm, keyPair.Sec, entries), Equals, false)
+}
+
+func (s *CSSuite) Test_DecryptBatchErrors(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csms := encryptMany(c, keyPair.Pub, 3)
+
+	r, err := cs.Curve.RandScalar(rand.Reader)
+	c.Assert(err, IsNil)
+	csms[1] = cs.encryptPoint(cs.Curve.Identity(), r, keyPair.Pub)
+
+	msgs, errs := cs.DecryptBatchWithWorkers(keyPair.Sec, csms, 1)
+
+	c.Assert(errs[0], IsNil)
+	c.Assert(msgs[0], DeepEquals, message)
+	c.Assert(errs[1], Equals, ErrInvalidCiphertext)
+	c.Assert(msgs[1], IsNil)
+	c.Assert(errs[2], IsNil)
+
+	msgs, errs = cs.DecryptBatchWithWorkers(nil, csms, 2)
+
+	for i := range csms {
+		c.Assert(errs[i], Equals, ErrInvalidKey)
+		c.Assert(msgs[i], IsNil)
+	}
+}
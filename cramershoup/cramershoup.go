@@ -7,6 +7,19 @@ import (
 	"github.com/twtiger/crypto/curve"
 )
 
+var (
+	// ErrInvalidCiphertext is returned when a message fails its validity
+	// check or decrypts to the identity. Both cases take the same time, so the
+	// error does not reveal which check failed.
+	ErrInvalidCiphertext = errors.New("cannot decrypt the message")
+	// ErrInvalidKey is returned when a public or secret key is missing parts
+	// or has a malformed point.
+	ErrInvalidKey = errors.New("not a valid key")
+	// ErrMalformedPoint is returned when a message has a point that is
+	// missing, not on the curve or the identity.
+	ErrMalformedPoint = errors.New("malformed point")
+)
+
 // CramerShoup instantiates a Cramer-Shoup system with a specific elliptic curve
 type CramerShoup struct {
 	Curve Curve
@@ -19,7 +32,13 @@ type Curve interface {
 	curve.PointDoubleScalarMultiplier
 	curve.PointCalculator
 	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
 	curve.PointDecoder
+	curve.StrictPointDecoder
+	curve.ScalarMultiplier
+	curve.ScalarCalculator
+	curve.ScalarDecoder
 	curve.Hasher
 }
 
@@ -66,6 +85,14 @@ func firstError(errs ...error) error {
 	return nil
 }
 
+func (cs *CramerShoup) publicKey(sec *SecretKey) *PublicKey {
+	return &PublicKey{
+		C: cs.Curve.PointDoubleScalarMul(cs.Curve.G(), sec.X1, cs.Curve.G2(), sec.X2),
+		D: cs.Curve.PointDoubleScalarMul(cs.Curve.G(), sec.Y1, cs.Curve.G2(), sec.Y2),
+		H: cs.Curve.PointScalarMul(cs.Curve.G(), sec.Z),
+	}
+}
+
 // GenerateKeys generates a key pair of Cramer-Shoup keys.
 func (cs *CramerShoup) GenerateKeys(rand io.Reader) (*KeyPair, error) {
 	sec, err := cs.deriveSecretKey(rand)
@@ -74,14 +101,58 @@ func (cs *CramerShoup) GenerateKeys(rand io.Reader) (*KeyPair, error) {
 	}
 	return &KeyPair{
 		Sec: sec,
-		Pub: &PublicKey{
-			C: cs.Curve.PointDoubleScalarMul(cs.Curve.G(), sec.X1, cs.Curve.G2(), sec.X2),
-			D: cs.Curve.PointDoubleScalarMul(cs.Curve.G(), sec.Y1, cs.Curve.G2(), sec.Y2),
-			H: cs.Curve.PointScalarMul(cs.Curve.G(), sec.Z),
-		},
+		Pub: cs.publicKey(sec),
+	}, nil
+}
+
+// DeriveKeysFromSeed deterministically derives a key pair of Cramer-Shoup
+// keys from a 32 or 57 byte seed. Each secret scalar is the curve's
+// HashToScalar (SHAKE-256 for Ed448-Goldilocks) of a distinct label followed
+// by the seed:
+//
+//	x1 = HashToScalar("cramershoup_x1" || seed)
+//	x2 = HashToScalar("cramershoup_x2" || seed)
+//	y1 = HashToScalar("cramershoup_y1" || seed)
+//	y2 = HashToScalar("cramershoup_y2" || seed)
+//	z  = HashToScalar("cramershoup_z" || seed)
+//
+// The same seed always produces the same key pair, so only the seed needs to
+// be backed up.
+func (cs *CramerShoup) DeriveKeysFromSeed(seed []byte) (*KeyPair, error) {
+	if len(seed) != 32 && len(seed) != 57 {
+		return nil, errors.New("invalid seed length")
+	}
+	sec := &SecretKey{
+		X1: cs.Curve.HashToScalar([]byte("cramershoup_x1"), seed),
+		X2: cs.Curve.HashToScalar([]byte("cramershoup_x2"), seed),
+		Y1: cs.Curve.HashToScalar([]byte("cramershoup_y1"), seed),
+		Y2: cs.Curve.HashToScalar([]byte("cramershoup_y2"), seed),
+		Z:  cs.Curve.HashToScalar([]byte("cramershoup_z"), seed),
+	}
+	return &KeyPair{
+		Sec: sec,
+		Pub: cs.publicKey(sec),
 	}, nil
 }
 
+func (cs *CramerShoup) isValidPoint(p curve.Point) bool {
+	return p != nil && cs.Curve.IsOnCurve(p) && !cs.Curve.EqualPoints(p, cs.Curve.Identity())
+}
+
+// ValidatePublicKey checks that every point of the public key is present, on
+// the curve and not the identity. Encrypt does not check the key, so keys
+// received from others should be checked once when they are loaded.
+func (cs *CramerShoup) ValidatePublicKey(pub *PublicKey) error {
+	if pub == nil || !(cs.isValidPoint(pub.C) && cs.isValidPoint(pub.D) && cs.isValidPoint(pub.H)) {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+func isValidSecretKey(sec *SecretKey) bool {
+	return sec != nil && sec.X1 != nil && sec.X2 != nil && sec.Y1 != nil && sec.Y2 != nil && sec.Z != nil
+}
+
 // Encrypt encrypts the given message to the given public key. The result is a
 // four points. Errors can result from reading random.
 func (cs *CramerShoup) Encrypt(message []byte, rand io.Reader, pub *PublicKey) (*CSMessage, error) {
@@ -90,13 +161,20 @@ func (cs *CramerShoup) Encrypt(message []byte, rand io.Reader, pub *PublicKey) (
 	if err != nil {
 		return nil, err
 	}
+	return cs.encrypt(message, r, pub), nil
+}
 
+func (cs *CramerShoup) encrypt(message []byte, r curve.Scalar, pub *PublicKey) *CSMessage {
+	return cs.encryptPoint(cs.Curve.DecodePoint(message), r, pub)
+}
+
+func (cs *CramerShoup) encryptPoint(m curve.Point, r curve.Scalar, pub *PublicKey) *CSMessage {
 	// u1 = G1*r, u2 = G2*r
 	u1 := cs.Curve.PointScalarMul(cs.Curve.G(), r)
 	u2 := cs.Curve.PointScalarMul(cs.Curve.G2(), r)
 
 	// e = (h*r) + m
-	e := cs.Curve.AddPoints(cs.Curve.PointScalarMul(pub.H, r), cs.Curve.DecodePoint(message))
+	e := cs.Curve.AddPoints(cs.Curve.PointScalarMul(pub.H, r), m)
 
 	// a = c * r
 	// alpha = H(u1,u2,e)
@@ -112,32 +190,52 @@ func (cs *CramerShoup) Encrypt(message []byte, rand io.Reader, pub *PublicKey) (
 		U2: u2,
 		E:  e,
 		V:  v,
-	}, nil
+	}
 }
 
-// Decrypt takes four points, resulting from an Cramer-Shoup encryption, and
-// returns the plaintext of the message. An error can result only if the
-// ciphertext is invalid.
-// XXX: check if message is zero
-func (cs *CramerShoup) Decrypt(sec *SecretKey, csm *CSMessage) ([]byte, error) {
+// expectedV computes the V a valid message has:
+// v = u1*(x1+y1*alpha) + u2*(x2+ y2*alpha)
+func (cs *CramerShoup) expectedV(sec *SecretKey, csm *CSMessage, alpha curve.Scalar) curve.Point {
 	// a = (u1*x1)+(u2*x2)
 	a := cs.Curve.PointDoubleScalarMul(csm.U1, sec.X1, csm.U2, sec.X2)
 
 	// b = (u1*y1)+(u2*y2)
 	b := cs.Curve.PointDoubleScalarMul(csm.U1, sec.Y1, csm.U2, sec.Y2)
 
-	// alpha = H(u1,u2,e)
-	alpha := cs.Curve.HashToScalar(csm.U1, csm.U2, csm.E)
-
-	// v = u1*(x1+y1*alpha) + u2*(x2+ y2*alpha)
-	v := cs.Curve.AddPoints(a, cs.Curve.PointScalarMul(b, alpha))
+	return cs.Curve.AddPoints(a, cs.Curve.PointScalarMul(b, alpha))
+}
 
-	// v == csm.v
-	if !cs.Curve.EqualPoints(v, csm.V) {
-		return nil, errors.New("cannot decrypt the message")
+// Decrypt takes four points, resulting from an Cramer-Shoup encryption, and
+// returns the plaintext of the message. An error can result only if the
+// secret key or the ciphertext is invalid: ErrInvalidKey, ErrMalformedPoint
+// or ErrInvalidCiphertext. Every check is computed before the message is
+// rejected, so an invalid ciphertext takes the same time whether it fails the
+// validity check or decrypts to the identity.
+func (cs *CramerShoup) Decrypt(sec *SecretKey, csm *CSMessage) ([]byte, error) {
+	if !isValidSecretKey(sec) {
+		return nil, ErrInvalidKey
+	}
+	if !hasPoints(csm) {
+		return nil, ErrMalformedPoint
 	}
+	wellFormed := cs.ValidateMessage(csm) == nil
+
+	// alpha = H(u1,u2,e)
+	alpha := cs.Curve.HashToScalar(csm.U1, csm.U2, csm.E)
+	v := cs.expectedV(sec, csm, alpha)
 
 	// m = e - u1*z
 	m := cs.Curve.SubPoints(csm.E, cs.Curve.PointScalarMul(csm.U1, sec.Z))
+
+	// v == csm.v, m != 0
+	valid := cs.Curve.EqualPoints(v, csm.V)
+	zero := cs.Curve.EqualPoints(m, cs.Curve.Identity())
+
+	if !wellFormed {
+		return nil, ErrMalformedPoint
+	}
+	if !valid || zero {
+		return nil, ErrInvalidCiphertext
+	}
 	return m.Encode(), nil
 }
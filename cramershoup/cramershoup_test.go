@@ -281,3 +281,160 @@ func (s *CSSuite) Test_ReturnFirstError(c *C) {
 
 	c.Assert(err, ErrorMatches, "new error 1")
 }
+
+func (s *CSSuite) Test_DeriveKeysFromSeed(c *C) {
+	seed := []byte{
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+		0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+		0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+		0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+	}
+
+	expSec := &SecretKey{
+		// x1
+		curve.Ed448GoldScalar([]byte{
+			0xda, 0x3d, 0x18, 0xb4, 0x45, 0xe8, 0xe3, 0xd9,
+			0xe5, 0x61, 0x95, 0x6f, 0x8c, 0x61, 0x51, 0x2a,
+			0x41, 0xdd, 0x29, 0x97, 0x6f, 0xcb, 0xd6, 0xf4,
+			0xdf, 0x6e, 0x95, 0xae, 0x47, 0xca, 0x85, 0x26,
+			0x61, 0x13, 0xc0, 0x80, 0x59, 0xf5, 0x8b, 0x16,
+			0x1a, 0x68, 0x55, 0x50, 0xdd, 0xd6, 0x9f, 0xd6,
+			0x82, 0xe1, 0x08, 0xbd, 0xa8, 0x67, 0x5c, 0x56,
+		}),
+		// x2
+		curve.Ed448GoldScalar([]byte{
+			0xc1, 0x28, 0x7f, 0xba, 0x92, 0x11, 0x2c, 0xb6,
+			0xea, 0x2d, 0xfb, 0xdf, 0xcc, 0xbc, 0x78, 0x94,
+			0x3f, 0x0e, 0xc3, 0xe3, 0x64, 0xe3, 0x4e, 0x25,
+			0xbd, 0xa3, 0x85, 0xca, 0xb9, 0x49, 0x30, 0x97,
+			0x14, 0x93, 0x00, 0x60, 0x48, 0xb1, 0x53, 0x8b,
+			0x6d, 0x1f, 0x54, 0xca, 0x17, 0x80, 0x32, 0x2e,
+			0x8a, 0x4a, 0x75, 0x5f, 0x08, 0x53, 0x06, 0xdf,
+		}),
+		// y1
+		curve.Ed448GoldScalar([]byte{
+			0x84, 0x9a, 0x07, 0xa0, 0x08, 0x1b, 0x9c, 0x97,
+			0x98, 0x45, 0x6f, 0xf9, 0x94, 0x07, 0x80, 0x53,
+			0x5a, 0xcd, 0xeb, 0x1a, 0x08, 0x70, 0xcf, 0x8a,
+			0x18, 0xa7, 0xab, 0x15, 0x2f, 0x46, 0x4d, 0xae,
+			0xcb, 0x15, 0x17, 0xaa, 0x34, 0xf9, 0x3a, 0xd2,
+			0x69, 0x43, 0xbe, 0x0c, 0xb1, 0x3b, 0x6c, 0x90,
+			0x10, 0x38, 0x6f, 0x11, 0x7b, 0xc1, 0xa8, 0xe5,
+		}),
+		// y2
+		curve.Ed448GoldScalar([]byte{
+			0xdb, 0x72, 0x0c, 0x4d, 0xef, 0x79, 0xb5, 0xb4,
+			0x2e, 0x3f, 0x8a, 0x5b, 0x22, 0x6e, 0xeb, 0x0d,
+			0x49, 0xb0, 0xc5, 0xa3, 0x5a, 0x7e, 0x40, 0x2f,
+			0x6a, 0x3d, 0x08, 0x16, 0x36, 0x29, 0x0a, 0x31,
+			0x87, 0x04, 0xe5, 0xd8, 0x38, 0x64, 0x00, 0xe7,
+			0x69, 0x31, 0x34, 0x18, 0xb2, 0x38, 0x6c, 0xf6,
+			0x25, 0x32, 0x25, 0x73, 0x57, 0x87, 0x68, 0x32,
+		}),
+		// z
+		curve.Ed448GoldScalar([]byte{
+			0x06, 0x7c, 0x81, 0x47, 0xb8, 0x34, 0x08, 0x8a,
+			0x91, 0xb1, 0x27, 0xa8, 0xfb, 0x77, 0xf3, 0xb5,
+			0xed, 0x84, 0x69, 0x37, 0x01, 0x04, 0x64, 0xda,
+			0x1e, 0xce, 0x9a, 0xb0, 0x32, 0xff, 0x3d, 0x17,
+			0x91, 0x1a, 0x75, 0x7f, 0x1a, 0x6b, 0x11, 0xc0,
+			0xc8, 0xc7, 0xe0, 0xae, 0x13, 0x09, 0x07, 0x7f,
+			0xba, 0x04, 0x37, 0x06, 0xd0, 0xba, 0xe8, 0x4a,
+		}),
+	}
+
+	keyPair, err := cs.DeriveKeysFromSeed(seed)
+
+	c.Assert(err, IsNil)
+	c.Assert(keyPair.Sec, DeepEquals, expSec)
+	c.Assert(keyPair.Pub, DeepEquals, cs.publicKey(expSec))
+
+	again, err := cs.DeriveKeysFromSeed(seed)
+
+	c.Assert(err, IsNil)
+	c.Assert(again, DeepEquals, keyPair)
+
+	longSeed := make([]byte, 57)
+	for i := range longSeed {
+		longSeed[i] = byte(i)
+	}
+	keyPair, err = cs.DeriveKeysFromSeed(longSeed)
+
+	c.Assert(err, IsNil)
+	c.Assert(keyPair.Sec.Z, DeepEquals, curve.Ed448GoldScalar([]byte{
+		0x83, 0x53, 0x13, 0x57, 0x42, 0x48, 0x47, 0xd7,
+		0x95, 0x55, 0x5b, 0x04, 0x0b, 0xc0, 0xce, 0xf2,
+		0xa9, 0x9d, 0xa5, 0x8c, 0x29, 0x76, 0xf7, 0x07,
+		0xfd, 0x2d, 0xcf, 0xd4, 0x58, 0xe0, 0x45, 0xfc,
+		0x3c, 0x8e, 0xa1, 0x02, 0xc8, 0x78, 0xda, 0x0a,
+		0xe3, 0x93, 0x1c, 0xa9, 0x61, 0x4d, 0x14, 0x67,
+		0xcb, 0x73, 0x53, 0xd7, 0xdb, 0xe3, 0x23, 0x3c,
+	}))
+
+	keyPair, err = cs.DeriveKeysFromSeed(seed[:31])
+
+	c.Assert(err, ErrorMatches, "invalid seed length")
+	c.Assert(keyPair, IsNil)
+}
+
+func (s *CSSuite) Test_DecryptErrors(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	csm, err := cs.Encrypt(message, rand.Reader, keyPair.Pub)
+	c.Assert(err, IsNil)
+
+	tampered := *csm
+	tampered.V = cs.Curve.AddPoints(csm.V, cs.Curve.G())
+	_, err = cs.Decrypt(keyPair.Sec, &tampered)
+
+	c.Assert(errors.Is(err, ErrInvalidCiphertext), Equals, true)
+
+	r, err := cs.Curve.RandScalar(rand.Reader)
+	c.Assert(err, IsNil)
+	zero := cs.encryptPoint(cs.Curve.Identity(), r, keyPair.Pub)
+	_, err = cs.Decrypt(keyPair.Sec, zero)
+
+	c.Assert(errors.Is(err, ErrInvalidCiphertext), Equals, true)
+
+	malformed := *csm
+	malformed.U2 = cs.Curve.Identity()
+	_, err = cs.Decrypt(keyPair.Sec, &malformed)
+
+	c.Assert(errors.Is(err, ErrMalformedPoint), Equals, true)
+
+	malformed.U2 = nil
+	_, err = cs.Decrypt(keyPair.Sec, &malformed)
+
+	c.Assert(errors.Is(err, ErrMalformedPoint), Equals, true)
+
+	_, err = cs.Decrypt(&SecretKey{X1: keyPair.Sec.X1}, csm)
+
+	c.Assert(errors.Is(err, ErrInvalidKey), Equals, true)
+
+	_, err = cs.Decrypt(nil, csm)
+
+	c.Assert(errors.Is(err, ErrInvalidKey), Equals, true)
+}
+
+func (s *CSSuite) Test_ValidatePublicKey(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	c.Assert(cs.ValidatePublicKey(keyPair.Pub), IsNil)
+
+	invalid := *keyPair.Pub
+	invalid.H = cs.Curve.Identity()
+	c.Assert(errors.Is(cs.ValidatePublicKey(&invalid), ErrInvalidKey), Equals, true)
+
+	invalid = *keyPair.Pub
+	invalid.C = nil
+	c.Assert(errors.Is(cs.ValidatePublicKey(&invalid), ErrInvalidKey), Equals, true)
+
+	c.Assert(errors.Is(cs.ValidatePublicKey(nil), ErrInvalidKey), Equals, true)
+
+	invalid.C = keyPair.Pub.C
+	invalid.H = cs.Curve.Identity()
+	_, err = cs.EncryptVerifiable(message, rand.Reader, &invalid)
+
+	c.Assert(errors.Is(err, ErrInvalidKey), Equals, true)
+}
@@ -0,0 +1,206 @@
+package cramershoup
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// KeyID identifies a key pair held in a Keyring.
+type KeyID uint32
+
+const keyIDSize = 4
+
+// KeyringEntry is a key pair held in a Keyring, with its expiry metadata.
+type KeyringEntry struct {
+	ID   KeyID
+	Pair *KeyPair
+	// Expires is the time after which the key is no longer used. The zero
+	// value means the key never expires.
+	Expires time.Time
+}
+
+func (e *KeyringEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Keyring holds several Cramer-Shoup key pairs, so messages encrypted to
+// previous keys can still be decrypted after the keys are rotated. New
+// messages are encrypted to the most recently added key that has not expired.
+type Keyring struct {
+	CS *CramerShoup
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	lock    sync.RWMutex
+	entries []*KeyringEntry
+}
+
+// KeyedMessage represents a Cramer-Shoup message with a header naming the key
+// it was encrypted to.
+type KeyedMessage struct {
+	KeyID KeyID
+	CSMessage
+}
+
+// Encode serializes the message as KeyID || U1 || U2 || E || V, with the key
+// ID in big-endian order.
+func (km *KeyedMessage) Encode() []byte {
+	b := make([]byte, keyIDSize)
+	binary.BigEndian.PutUint32(b, uint32(km.KeyID))
+	return append(b, km.CSMessage.Encode()...)
+}
+
+// DecodeKeyedMessage deserializes and validates a message encoded by
+// KeyedMessage.Encode.
+func (cs *CramerShoup) DecodeKeyedMessage(b []byte) (*KeyedMessage, error) {
+	if len(b) < keyIDSize {
+		return nil, ErrInvalidCiphertext
+	}
+	csm, err := cs.DecodeMessage(b[keyIDSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &KeyedMessage{
+		KeyID:     KeyID(binary.BigEndian.Uint32(b)),
+		CSMessage: *csm,
+	}, nil
+}
+
+func (k *Keyring) now() time.Time {
+	if k.Now == nil {
+		return time.Now()
+	}
+	return k.Now()
+}
+
+// Add adds a key pair to the keyring under the given ID. A zero expires means
+// the key never expires. ErrInvalidKey is returned if the pair is missing
+// either key or the public key is invalid.
+func (k *Keyring) Add(id KeyID, pair *KeyPair, expires time.Time) error {
+	if pair == nil || !isValidSecretKey(pair.Sec) || k.CS.ValidatePublicKey(pair.Pub) != nil {
+		return ErrInvalidKey
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	for _, e := range k.entries {
+		if e.ID == id {
+			return errors.New("duplicate key id")
+		}
+	}
+	k.entries = append(k.entries, &KeyringEntry{ID: id, Pair: pair, Expires: expires})
+	return nil
+}
+
+// Remove removes the key pair with the given ID from the keyring.
+func (k *Keyring) Remove(id KeyID) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	for i, e := range k.entries {
+		if e.ID == id {
+			k.entries = append(k.entries[:i], k.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Prune removes all expired key pairs from the keyring.
+func (k *Keyring) Prune() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := k.now()
+	entries := k.entries[:0]
+	for _, e := range k.entries {
+		if !e.expired(now) {
+			entries = append(entries, e)
+		}
+	}
+	k.entries = entries
+}
+
+// Entries returns the key pairs held in the keyring, oldest first.
+func (k *Keyring) Entries() []*KeyringEntry {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return append([]*KeyringEntry{}, k.entries...)
+}
+
+// Current returns the most recently added key pair that has not expired.
+func (k *Keyring) Current() (*KeyringEntry, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	now := k.now()
+	for i := len(k.entries) - 1; i >= 0; i-- {
+		if !k.entries[i].expired(now) {
+			return k.entries[i], nil
+		}
+	}
+	return nil, errors.New("no current key")
+}
+
+// Encrypt encrypts the given message to the current key, and names that key
+// in the message header. Errors can result from reading random or if the
+// keyring has no current key.
+func (k *Keyring) Encrypt(message []byte, rand io.Reader) (*KeyedMessage, error) {
+	e, err := k.Current()
+	if err != nil {
+		return nil, err
+	}
+	csm, err := k.CS.Encrypt(message, rand, e.Pair.Pub)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyedMessage{KeyID: e.ID, CSMessage: *csm}, nil
+}
+
+// Decrypt decrypts the given message with the key named in its header. An
+// error results if the key is unknown or expired, or if the message is
+// invalid.
+func (k *Keyring) Decrypt(km *KeyedMessage) ([]byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	now := k.now()
+	for _, e := range k.entries {
+		if e.ID != km.KeyID {
+			continue
+		}
+		if e.expired(now) {
+			return nil, errors.New("key expired")
+		}
+		return k.CS.Decrypt(e.Pair.Sec, &km.CSMessage)
+	}
+	return nil, errors.New("unknown key")
+}
+
+// DecryptAny decrypts a message without a key header by trying every key
+// that has not expired. Every key is always tried, oldest first, so the time
+// taken does not depend on which key the message was encrypted to.
+func (k *Keyring) DecryptAny(csm *CSMessage) ([]byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	now := k.now()
+	var message []byte
+	for _, e := range k.entries {
+		if e.expired(now) {
+			continue
+		}
+		m, err := k.CS.Decrypt(e.Pair.Sec, csm)
+		if err == nil && message == nil {
+			message = m
+		}
+	}
+	if message == nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return message, nil
+}
@@ -0,0 +1,130 @@
+package cramershoup
+
+import (
+	"crypto/rand"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func newTestKeyring(c *C, now time.Time) (*Keyring, *KeyPair, *KeyPair) {
+	oldPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	newPair, err := cs.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	k := &Keyring{CS: cs, Now: func() time.Time { return now }}
+	c.Assert(k.Add(1, oldPair, now.Add(time.Hour)), IsNil)
+	c.Assert(k.Add(2, newPair, time.Time{}), IsNil)
+	return k, oldPair, newPair
+}
+
+func (s *CSSuite) Test_KeyringEncryptsToCurrentKey(c *C) {
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, _, newPair := newTestKeyring(c, now)
+
+	current, err := k.Current()
+	c.Assert(err, IsNil)
+	c.Assert(current.ID, Equals, KeyID(2))
+
+	km, err := k.Encrypt(message, rand.Reader)
+	c.Assert(err, IsNil)
+	c.Assert(km.KeyID, Equals, KeyID(2))
+
+	m, err := cs.Decrypt(newPair.Sec, &km.CSMessage)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, message)
+
+	m, err = k.Decrypt(km)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, message)
+
+	c.Assert(k.Add(2, newPair, time.Time{}), ErrorMatches, "duplicate key id")
+
+	c.Assert(k.Add(3, nil, time.Time{}), Equals, ErrInvalidKey)
+	c.Assert(k.Add(3, &KeyPair{Pub: newPair.Pub}, time.Time{}), Equals, ErrInvalidKey)
+	c.Assert(k.Add(3, &KeyPair{Pub: newPair.Pub, Sec: &SecretKey{X1: newPair.Sec.X1}}, time.Time{}), Equals, ErrInvalidKey)
+	c.Assert(k.Add(3, &KeyPair{Sec: newPair.Sec}, time.Time{}), Equals, ErrInvalidKey)
+	c.Assert(k.Entries(), HasLen, 2)
+
+	_, err = k.Encrypt(message, testHelpers.FixedRandReader([]byte{0x00}))
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *CSSuite) Test_KeyringDecryptsWithPreviousKeyUntilExpiry(c *C) {
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, oldPair, _ := newTestKeyring(c, now)
+
+	csm, err := cs.Encrypt(message, rand.Reader, oldPair.Pub)
+	c.Assert(err, IsNil)
+	km := &KeyedMessage{KeyID: 1, CSMessage: *csm}
+
+	m, err := k.Decrypt(km)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, message)
+
+	m, err = k.DecryptAny(csm)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, message)
+
+	k.Now = func() time.Time { return now.Add(time.Hour) }
+
+	_, err = k.Decrypt(km)
+	c.Assert(err, ErrorMatches, "key expired")
+
+	_, err = k.DecryptAny(csm)
+	c.Assert(err, Equals, ErrInvalidCiphertext)
+
+	km.KeyID = 3
+	_, err = k.Decrypt(km)
+	c.Assert(err, ErrorMatches, "unknown key")
+
+	k.Prune()
+	c.Assert(k.Entries(), HasLen, 1)
+	c.Assert(k.Entries()[0].ID, Equals, KeyID(2))
+
+	k.Remove(2)
+	_, err = k.Current()
+	c.Assert(err, ErrorMatches, "no current key")
+
+	_, err = k.Encrypt(message, rand.Reader)
+	c.Assert(err, ErrorMatches, "no current key")
+}
+
+func (s *CSSuite) Test_KeyringDecryptsWithWrongKeyID(c *C) {
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, oldPair, _ := newTestKeyring(c, now)
+
+	csm, err := cs.Encrypt(message, rand.Reader, oldPair.Pub)
+	c.Assert(err, IsNil)
+
+	_, err = k.Decrypt(&KeyedMessage{KeyID: 2, CSMessage: *csm})
+	c.Assert(err, Equals, ErrInvalidCiphertext)
+}
+
+func (s *CSSuite) Test_EncodeAndDecodeKeyedMessage(c *C) {
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, _, _ := newTestKeyring(c, now)
+
+	km, err := k.Encrypt(message, rand.Reader)
+	c.Assert(err, IsNil)
+
+	encoded := km.Encode()
+	c.Assert(encoded[:4], DeepEquals, []byte{0x00, 0x00, 0x00, 0x02})
+
+	decoded, err := cs.DecodeKeyedMessage(encoded)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.KeyID, Equals, KeyID(2))
+
+	m, err := k.Decrypt(decoded)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, message)
+
+	_, err = cs.DecodeKeyedMessage(encoded[:3])
+	c.Assert(err, Equals, ErrInvalidCiphertext)
+
+	_, err = cs.DecodeKeyedMessage(encoded[:5])
+	c.Assert(err, Equals, ErrInvalidCiphertext)
+}
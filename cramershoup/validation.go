@@ -0,0 +1,178 @@
+package cramershoup
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// Proof is a non-interactive proof of knowledge of the randomness r used to
+// create a Cramer-Shoup message, such that U1 = G1*r and U2 = G2*r.
+type Proof struct {
+	C, S curve.Scalar
+}
+
+// VerifiableMessage represents a Cramer-Shoup message with an attached proof
+// of knowledge of its randomness. Anyone holding the recipient's public key
+// can check it without being able to decrypt it.
+type VerifiableMessage struct {
+	CSMessage
+	Proof *Proof
+}
+
+// Encode serializes the message as U1 || U2 || E || V.
+func (csm *CSMessage) Encode() []byte {
+	return curve.Append(csm.U1, csm.U2, csm.E, csm.V)
+}
+
+// Encode serializes the message as U1 || U2 || E || V || c || s.
+func (vm *VerifiableMessage) Encode() []byte {
+	return curve.Append(vm.CSMessage.Encode(), vm.Proof.C, vm.Proof.S)
+}
+
+func (cs *CramerShoup) pointSize() int {
+	return len(cs.Curve.G().Encode())
+}
+
+func (cs *CramerShoup) scalarSize() int {
+	return len(cs.Curve.Q().Encode())
+}
+
+// ValidateMessage checks that every point of the message is present, on the
+// curve and not the identity. It needs no secret key, so it can be used to
+// drop malformed messages before they reach Decrypt.
+func (cs *CramerShoup) ValidateMessage(csm *CSMessage) error {
+	if !hasPoints(csm) {
+		return ErrMalformedPoint
+	}
+	for _, p := range []curve.Point{csm.U1, csm.U2, csm.E, csm.V} {
+		if !cs.isValidPoint(p) {
+			return ErrMalformedPoint
+		}
+	}
+	return nil
+}
+
+func hasPoints(csm *CSMessage) bool {
+	return csm != nil && csm.U1 != nil && csm.U2 != nil && csm.E != nil && csm.V != nil
+}
+
+// DecodeMessage deserializes and validates a message encoded by
+// CSMessage.Encode. It returns ErrInvalidCiphertext if the message has the
+// wrong length and ErrMalformedPoint if any point is invalid.
+func (cs *CramerShoup) DecodeMessage(b []byte) (*CSMessage, error) {
+	size := cs.pointSize()
+	if len(b) != 4*size {
+		return nil, ErrInvalidCiphertext
+	}
+
+	var ps [4]curve.Point
+	for i := range ps {
+		p, err := cs.Curve.DecodePointStrict(b[i*size : (i+1)*size])
+		if err != nil {
+			return nil, ErrMalformedPoint
+		}
+		ps[i] = p
+	}
+
+	csm := &CSMessage{U1: ps[0], U2: ps[1], E: ps[2], V: ps[3]}
+	if err := cs.ValidateMessage(csm); err != nil {
+		return nil, err
+	}
+	return csm, nil
+}
+
+// DecodeVerifiableMessage deserializes and validates a message encoded by
+// VerifiableMessage.Encode. The proof is not checked; use VerifyMessage.
+func (cs *CramerShoup) DecodeVerifiableMessage(b []byte) (*VerifiableMessage, error) {
+	size := 4 * cs.pointSize()
+	if len(b) != size+2*cs.scalarSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	csm, err := cs.DecodeMessage(b[:size])
+	if err != nil {
+		return nil, err
+	}
+	c, err := cs.Curve.DecodeScalar(b[size : size+cs.scalarSize()])
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	s, err := cs.Curve.DecodeScalar(b[size+cs.scalarSize():])
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return &VerifiableMessage{
+		CSMessage: *csm,
+		Proof:     &Proof{C: c, S: s},
+	}, nil
+}
+
+func (cs *CramerShoup) proofChallenge(pub *PublicKey, csm *CSMessage, t1, t2 curve.Point) curve.Scalar {
+	// c = HashToScalar("cramershoup_proof" || G1 || G2 || C || D || H || U1 || U2 || E || V || T1 || T2)
+	return cs.Curve.HashToScalar([]byte("cramershoup_proof"),
+		cs.Curve.G(), cs.Curve.G2(),
+		pub.C, pub.D, pub.H,
+		csm.U1, csm.U2, csm.E, csm.V,
+		t1, t2)
+}
+
+// EncryptVerifiable encrypts the given message to the given public key and
+// attaches a proof of knowledge of the encryption randomness. The proof binds
+// the whole message, so it cannot be moved to a modified message. Errors can
+// result from reading random or from an invalid public key.
+func (cs *CramerShoup) EncryptVerifiable(message []byte, rand io.Reader, pub *PublicKey) (*VerifiableMessage, error) {
+	if err := cs.ValidatePublicKey(pub); err != nil {
+		return nil, err
+	}
+	r, err := cs.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	t, err := cs.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	csm := cs.encrypt(message, r, pub)
+
+	// T1 = G1*t, T2 = G2*t
+	t1 := cs.Curve.PointScalarMul(cs.Curve.G(), t)
+	t2 := cs.Curve.PointScalarMul(cs.Curve.G2(), t)
+
+	// s = t - c*r (mod q)
+	c := cs.proofChallenge(pub, csm, t1, t2)
+	s := cs.Curve.SubScalars(t, cs.Curve.Mul(c, r))
+
+	return &VerifiableMessage{
+		CSMessage: *csm,
+		Proof:     &Proof{C: c, S: s},
+	}, nil
+}
+
+// VerifyMessage checks that the message is well formed and that its proof is
+// valid for the given public key. It needs no secret key. It returns
+// ErrInvalidKey, ErrMalformedPoint or ErrInvalidCiphertext.
+func (cs *CramerShoup) VerifyMessage(pub *PublicKey, vm *VerifiableMessage) error {
+	if err := cs.ValidatePublicKey(pub); err != nil {
+		return err
+	}
+	if err := cs.ValidateMessage(&vm.CSMessage); err != nil {
+		return err
+	}
+	if vm.Proof == nil || vm.Proof.C == nil || vm.Proof.S == nil {
+		return ErrInvalidCiphertext
+	}
+
+	// T1 = G1*s + U1*c, T2 = G2*s + U2*c
+	t1 := cs.Curve.PointDoubleScalarMul(cs.Curve.G(), vm.Proof.S, vm.U1, vm.Proof.C)
+	t2 := cs.Curve.PointDoubleScalarMul(cs.Curve.G2(), vm.Proof.S, vm.U2, vm.Proof.C)
+
+	// the proof is public, so the challenges are compared by their encodings
+	if !bytes.Equal(vm.Proof.C.Encode(), cs.proofChallenge(pub, &vm.CSMessage, t1, t2).Encode()) {
+		return ErrInvalidCiphertext
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cramershoup
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *CSSuite) Test_EncodeAndDecodeMessage(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	csm, err := cs.Encrypt(message, rand.Reader, keyPair.Pub)
+
+	decoded, err := cs.DecodeMessage(csm.Encode())
+
+	c.Assert(err, IsNil)
+	c.Assert(decoded.Encode(), DeepEquals, csm.Encode())
+
+	expMessage, err := cs.Decrypt(keyPair.Sec, decoded)
+
+	c.Assert(err, IsNil)
+	c.Assert(expMessage, DeepEquals, message)
+
+	_, err = cs.DecodeMessage(csm.Encode()[1:])
+
+	c.Assert(err, Equals, ErrInvalidCiphertext)
+
+	invalid := csm.Encode()
+	for i := 0; i < 56; i++ {
+		invalid[i] = 0xff
+	}
+	_, err = cs.DecodeMessage(invalid)
+
+	c.Assert(err, Equals, ErrMalformedPoint)
+}
+
+func (s *CSSuite) Test_ValidateMessage(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	csm, err := cs.Encrypt(message, rand.Reader, keyPair.Pub)
+
+	c.Assert(cs.ValidateMessage(csm), IsNil)
+
+	identity := *csm
+	identity.U2 = cs.Curve.Identity()
+	c.Assert(cs.ValidateMessage(&identity), Equals, ErrMalformedPoint)
+
+	_, err = cs.DecodeMessage(identity.Encode())
+	c.Assert(err, Equals, ErrMalformedPoint)
+
+	missing := *csm
+	missing.V = nil
+	c.Assert(cs.ValidateMessage(&missing), Equals, ErrMalformedPoint)
+}
+
+func (s *CSSuite) Test_VerifiableEncryption(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	vm, err := cs.EncryptVerifiable(message, rand.Reader, keyPair.Pub)
+
+	c.Assert(err, IsNil)
+	c.Assert(cs.VerifyMessage(keyPair.Pub, vm), IsNil)
+
+	decoded, err := cs.DecodeVerifiableMessage(vm.Encode())
+
+	c.Assert(err, IsNil)
+	c.Assert(cs.VerifyMessage(keyPair.Pub, decoded), IsNil)
+
+	expMessage, err := cs.Decrypt(keyPair.Sec, &decoded.CSMessage)
+
+	c.Assert(err, IsNil)
+	c.Assert(expMessage, DeepEquals, message)
+
+	_, err = cs.DecodeVerifiableMessage(vm.Encode()[1:])
+
+	c.Assert(err, Equals, ErrInvalidCiphertext)
+}
+
+func (s *CSSuite) Test_VerifyMessageRejectsTampering(c *C) {
+	keyPair, err := cs.GenerateKeys(rand.Reader)
+	otherKeyPair, err := cs.GenerateKeys(rand.Reader)
+	vm, err := cs.EncryptVerifiable(message, rand.Reader, keyPair.Pub)
+
+	c.Assert(cs.VerifyMessage(otherKeyPair.Pub, vm), Equals, ErrInvalidCiphertext)
+
+	tampered := *vm
+	tampered.E = cs.Curve.AddPoints(vm.E, cs.Curve.G())
+	c.Assert(cs.VerifyMessage(keyPair.Pub, &tampered), Equals, ErrInvalidCiphertext)
+
+	tampered = *vm
+	tampered.U1 = cs.Curve.AddPoints(vm.U1, cs.Curve.G())
+	c.Assert(cs.VerifyMessage(keyPair.Pub, &tampered), Equals, ErrInvalidCiphertext)
+
+	tampered = *vm
+	tampered.Proof = &Proof{C: vm.Proof.S, S: vm.Proof.C}
+	c.Assert(cs.VerifyMessage(keyPair.Pub, &tampered), Equals, ErrInvalidCiphertext)
+
+	tampered = *vm
+	tampered.Proof = nil
+	c.Assert(cs.VerifyMessage(keyPair.Pub, &tampered), Equals, ErrInvalidCiphertext)
+
+	_, err = cs.EncryptVerifiable(message, testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
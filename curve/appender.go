@@ -6,6 +6,13 @@ type Hasher interface {
 	HashToScalar(items ...interface{}) Scalar
 }
 
+// PointHasher is an interface for appending and hashing points, scalars, and
+// bytes into a point whose discrete logarithm is unknown
+// Implementers may determine which hash is used
+type PointHasher interface {
+	HashToPoint(items ...interface{}) Point
+}
+
 // Append accepts points, scalars, and bytes and returns a slice of bytes
 // Append will panic if the input contains items that are not of type point,
 // scalar, or bytes
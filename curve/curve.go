@@ -18,6 +18,12 @@ type BasicCurve interface {
 	RandLongTermScalar(io.Reader) (Scalar, error)
 }
 
+// Identifier names a curve in serialized keys, so that keys for one curve
+// are not loaded for another
+type Identifier interface {
+	ID() byte
+}
+
 // SecondGenerator is an interface for retrieving a second generator on a curve
 type SecondGenerator interface {
 	G2() Point
@@ -28,6 +34,17 @@ type PointDecoder interface {
 	DecodePoint([]byte) Point
 }
 
+// StrictPointDecoder will decode points for the curve, returning an error if
+// the bytes are not a valid point encoding
+type StrictPointDecoder interface {
+	DecodePointStrict([]byte) (Point, error)
+}
+
+// ScalarDecoder will decode scalars for the curve
+type ScalarDecoder interface {
+	DecodeScalar([]byte) (Scalar, error)
+}
+
 // PrecomputedMultiplier will use precomputed tables to perform point scalar multiplication
 // on the base point with a given scalar
 type PrecomputedMultiplier interface {
@@ -40,6 +57,14 @@ type PointDoubleScalarMultiplier interface {
 	PointDoubleScalarMul(p1 Point, s1 Scalar, p2 Point, s2 Scalar) Point
 }
 
+// MultiScalarMultiplier will add the results of many point scalar multiplications
+// The result is p1 * s1 + p2 * s2 + ... + pn * sn
+// Implementations may run in variable time, so this should only be used with
+// public or randomly blinded scalars
+type MultiScalarMultiplier interface {
+	MultiScalarMul([]Point, []Scalar) Point
+}
+
 // PointCalculator computes point arithmetic
 type PointCalculator interface {
 	AddPoints(Point, Point) Point
@@ -56,6 +81,24 @@ type PointValidator interface {
 	IsOnCurve(Point) bool
 }
 
+// IdentityPoint returns the identity element of the curve group
+type IdentityPoint interface {
+	Identity() Point
+}
+
+// CofactorClearer maps a point into the prime order subgroup by multiplying
+// it by the cofactor of the curve
+type CofactorClearer interface {
+	ClearCofactor(Point) Point
+}
+
+// EdDSAEncoder encodes and decodes points as the EdDSA signatures of RFC
+// 8032 do, returning an error if the bytes are not a valid encoding
+type EdDSAEncoder interface {
+	EncodeEdDSA(Point) []byte
+	DecodeEdDSA([]byte) (Point, error)
+}
+
 // Point is the point interface required for interacting with the included cryptosystems
 type Point interface {
 	Encode() []byte
@@ -71,11 +114,22 @@ type ScalarMultiplier interface {
 	Mul(Scalar, Scalar) Scalar
 }
 
-// ScalarCalculator computes point arithmetic
+// ScalarCalculator computes scalar arithmetic
 type ScalarCalculator interface {
 	SubScalars(Scalar, Scalar) Scalar
 }
 
+// ScalarAdder adds two scalars
+type ScalarAdder interface {
+	AddScalars(Scalar, Scalar) Scalar
+}
+
+// ScalarInverter computes the multiplicative inverse of a scalar modulo the
+// prime order
+type ScalarInverter interface {
+	InvertScalar(Scalar) Scalar
+}
+
 // ScalarComparer checks whether two scalars are equal
 type ScalarComparer interface {
 	EqualScalars(Scalar, Scalar) bool
@@ -1,6 +1,7 @@
 package curve
 
 import (
+	"bytes"
 	"errors"
 	"io"
 
@@ -51,6 +52,14 @@ func unwrapScalar(in Scalar) ed448.Scalar {
 // Ed448Gold is the implementation of Ed448-Goldilocks
 type Ed448Gold struct{}
 
+// Ed448GoldID is the identifier of Ed448-Goldilocks in serialized keys
+const Ed448GoldID = 0x01
+
+// ID returns the identifier of Ed448-Goldilocks
+func (c *Ed448Gold) ID() byte {
+	return Ed448GoldID
+}
+
 // Ed448GoldScalar returns a new Ed448-Goldilocks scalar
 func Ed448GoldScalar(bs []byte) Scalar {
 	return wrapScalar(ed448.NewScalar(bs))
@@ -159,6 +168,126 @@ func (c *Ed448Gold) DecodePoint(bs []byte) Point {
 	return wrapPoint(p)
 }
 
+// DecodePointStrict implements Point decoding for Ed448-Goldilocks, returning
+// an error if the bytes are not a valid encoding of a point
+func (c *Ed448Gold) DecodePointStrict(bs []byte) (Point, error) {
+	p := ed448.NewPointFromBytes()
+	valid, err := p.Decode(bs, false)
+	if err != nil || !valid {
+		return nil, errors.New("not a valid point")
+	}
+	return wrapPoint(p), nil
+}
+
+// DecodeScalar implements Scalar decoding for Ed448-Goldilocks
+func (c *Ed448Gold) DecodeScalar(bs []byte) (Scalar, error) {
+	if len(bs) != scalarSize {
+		return nil, errors.New("not a valid scalar")
+	}
+	s := Ed448GoldScalar(bs)
+	if !bytes.Equal(s.Encode(), bs) {
+		return nil, errors.New("not a valid scalar")
+	}
+	return s, nil
+}
+
+// Identity returns the Ed448-Goldilocks identity point
+func (c *Ed448Gold) Identity() Point {
+	p := ed448.NewPointFromBytes()
+	p.Sub(ed448.BasePoint, ed448.BasePoint)
+	return wrapPoint(p)
+}
+
+// ClearCofactor multiplies a point by 4, the cofactor of Ed448-Goldilocks
+func (c *Ed448Gold) ClearCofactor(p Point) Point {
+	twice := ed448.NewPointFromBytes()
+	twice.Add(unwrapPoint(p), unwrapPoint(p))
+	four := ed448.NewPointFromBytes()
+	four.Add(twice, twice)
+	return wrapPoint(four)
+}
+
+func addPoints(p1, p2 ed448.Point) ed448.Point {
+	if p1 == nil {
+		return p2
+	}
+	p := ed448.NewPointFromBytes()
+	p.Add(p1, p2)
+	return p
+}
+
+func msmWindow(n int) uint {
+	switch {
+	case n < 16:
+		return 3
+	case n < 128:
+		return 5
+	case n < 1024:
+		return 7
+	}
+	return 9
+}
+
+// scalarDigit returns the w bits of the little-endian bs starting at bit pos
+func scalarDigit(bs []byte, pos, w uint) int {
+	d := 0
+	for i := uint(0); i < w; i++ {
+		b := (pos + i) / 8
+		if b < uint(len(bs)) {
+			d |= int(bs[b]>>((pos+i)%8)&1) << i
+		}
+	}
+	return d
+}
+
+// MultiScalarMul computes p1 * s1 + p2 * s2 + ... + pn * sn with the bucket
+// (Pippenger) method. It runs in variable time and must only be used with
+// public or randomly blinded scalars.
+func (c *Ed448Gold) MultiScalarMul(ps []Point, ss []Scalar) Point {
+	if len(ps) != len(ss) {
+		panic("programmer error: mismatched points and scalars")
+	}
+
+	w := msmWindow(len(ps))
+	encoded := make([][]byte, len(ss))
+	for i, s := range ss {
+		encoded[i] = unwrapScalar(s).Encode()
+	}
+
+	var acc ed448.Point
+	for pos := int((scalarSize*8+w-1)/w-1) * int(w); pos >= 0; pos -= int(w) {
+		for i := uint(0); i < w && acc != nil; i++ {
+			acc = addPoints(acc, acc)
+		}
+
+		buckets := make([]ed448.Point, 1<<w)
+		for i, e := range encoded {
+			if d := scalarDigit(e, uint(pos), w); d != 0 {
+				buckets[d] = addPoints(buckets[d], unwrapPoint(ps[i]))
+			}
+		}
+
+		// sum of d * buckets[d], using running sums
+		var running, sum ed448.Point
+		for d := len(buckets) - 1; d > 0; d-- {
+			if buckets[d] != nil {
+				running = addPoints(running, buckets[d])
+			}
+			if running != nil {
+				sum = addPoints(sum, running)
+			}
+		}
+		if sum != nil {
+			acc = addPoints(acc, sum)
+		}
+	}
+
+	if acc == nil {
+		return c.Identity()
+	}
+	return wrapPoint(acc)
+}
+
 // PointDoubleScalarMul implements double point scalar multiplication
 // resulting in p1 * s1 + p2 * s2
 func (c *Ed448Gold) PointDoubleScalarMul(p1 Point, s1 Scalar, p2 Point, s2 Scalar) Point {
@@ -182,6 +311,13 @@ func (c *Ed448Gold) Mul(s1 Scalar, s2 Scalar) Scalar {
 	return wrapScalar(s)
 }
 
+// AddScalars adds two scalars
+func (c *Ed448Gold) AddScalars(s1 Scalar, s2 Scalar) Scalar {
+	s := ed448.NewScalar()
+	s.Add(unwrapScalar(s1), unwrapScalar(s2))
+	return wrapScalar(s)
+}
+
 // SubScalars subtracts two scalars
 func (c *Ed448Gold) SubScalars(s1 Scalar, s2 Scalar) Scalar {
 	s := ed448.NewScalar()
@@ -189,6 +325,39 @@ func (c *Ed448Gold) SubScalars(s1 Scalar, s2 Scalar) Scalar {
 	return wrapScalar(s)
 }
 
+// orderMinusTwo is q - 2 in little-endian order, the exponent used to invert
+// scalars
+var orderMinusTwo = [scalarSize]byte{
+	0xf1, 0x44, 0x58, 0xab, 0x92, 0xc2, 0x78, 0x23,
+	0x55, 0x8f, 0xc5, 0x8d, 0x72, 0xc2, 0x6c, 0x21,
+	0x90, 0x36, 0xd6, 0xae, 0x49, 0xdb, 0x4e, 0xc4,
+	0xe9, 0x23, 0xca, 0x7c, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x3f,
+}
+
+// InvertScalar computes the multiplicative inverse of a scalar as s^(q-2),
+// using the same sequence of multiplications for every scalar. The inverse of
+// zero is zero.
+func (c *Ed448Gold) InvertScalar(s Scalar) Scalar {
+	in := unwrapScalar(s)
+	var one [scalarSize]byte
+	one[0] = 1
+	result := ed448.NewScalar(one[:])
+	for i := len(orderMinusTwo)*8 - 1; i >= 0; i-- {
+		sq := ed448.NewScalar()
+		sq.Mul(result, result)
+		result = sq
+		if orderMinusTwo[i/8]>>uint(i%8)&1 == 1 {
+			m := ed448.NewScalar()
+			m.Mul(result, in)
+			result = m
+		}
+	}
+	return wrapScalar(result)
+}
+
 // EqualScalars compares two scalar values for equality
 func (c *Ed448Gold) EqualScalars(s1 Scalar, s2 Scalar) bool {
 	return unwrapScalar(s1).Equals(unwrapScalar(s2))
@@ -200,3 +369,20 @@ func (c *Ed448Gold) HashToScalar(items ...interface{}) Scalar {
 	sha3.ShakeSum256(hash, Append(items...))
 	return Ed448GoldScalar(hash)
 }
+
+// HashToPoint will append and hash bytes, points, and scalars into a point,
+// appending a counter and hashing again until the result is a valid point
+// encoding other than the identity. The number of attempts depends on the
+// input, so it should only be used with public inputs.
+func (c *Ed448Gold) HashToPoint(items ...interface{}) Point {
+	in := Append(items...)
+	identity := c.Identity()
+	for ctr := uint32(0); ; ctr++ {
+		hash := make([]byte, 56)
+		sha3.ShakeSum256(hash, Append(in, []byte{byte(ctr >> 24), byte(ctr >> 16), byte(ctr >> 8), byte(ctr)}))
+		p, err := c.DecodePointStrict(hash)
+		if err == nil && !c.EqualPoints(p, identity) {
+			return p
+		}
+	}
+}
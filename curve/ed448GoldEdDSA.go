@@ -0,0 +1,179 @@
+package curve
+
+import (
+	"errors"
+	"math/big"
+)
+
+// The ed448 backend keeps points on the twisted Edwards curve
+// -x^2 + y^2 = 1 - 39082*x^2*y^2 and encodes them with decaf, which does not
+// tell apart points that differ by a point of order 4. The 4-isogeny
+//
+//	phi(x, y) = (2xy / (y^2 - x^2), (x^2 + y^2) / (2 - x^2 - y^2))
+//
+// maps the Edwards curve of RFC 8032, x^2 + y^2 = 1 - 39081*x^2*y^2, onto the
+// backend's curve, and its dual
+//
+//	phiDual(x, y) = (2xy / (x^2 + y^2), (y^2 - x^2) / (2 + x^2 - y^2))
+//
+// maps it back, with phiDual(phi(P)) = 4*P. Both send the points of order 4
+// to the identity, so they are well defined on decaf points, and the formulas
+// have no exceptional points over GF(p). The base point of the backend is
+// phi(-B), where B is the base point of RFC 8032, so a backend point P stands
+// for the RFC 8032 point phiDual(-P/4).
+//
+// These maps are only applied to public points, so they use math/big.
+
+const (
+	edDSAPointSize = 57
+	limbBits       = 28
+)
+
+var (
+	// fieldPrime is p = 2^448 - 2^224 - 1
+	fieldPrime, _ = new(big.Int).SetString(
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffe"+
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+	halfPrime = new(big.Int).Rsh(fieldPrime, 1)
+	// sqrtExp is (p+1)/4, so that a^sqrtExp is a square root of a square a
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(fieldPrime, big.NewInt(1)), 2)
+	// invSqrtExp is (p-3)/4, so that a^invSqrtExp is 1/sqrt(a) for a square a
+	invSqrtExp = new(big.Int).Rsh(new(big.Int).Sub(fieldPrime, big.NewInt(3)), 2)
+
+	edwardsD = big.NewInt(-39081)
+	twistedD = big.NewInt(-39082)
+)
+
+// minusQuarter is -1/4 modulo q
+var minusQuarter = Ed448GoldScalar([]byte{
+	0xb6, 0x33, 0x82, 0x00, 0xee, 0x91, 0x9a, 0xda,
+	0x7f, 0x2b, 0x54, 0xea, 0xd5, 0x91, 0x11, 0x19,
+	0xec, 0xa8, 0x20, 0x43, 0x77, 0x24, 0x3b, 0x53,
+	0xef, 0x9a, 0x97, 0x9d, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x2f,
+})
+
+func fieldReduce(a *big.Int) *big.Int { return a.Mod(a, fieldPrime) }
+
+func fieldMul(a, b *big.Int) *big.Int { return fieldReduce(new(big.Int).Mul(a, b)) }
+
+func fieldAdd(a, b *big.Int) *big.Int { return fieldReduce(new(big.Int).Add(a, b)) }
+
+func fieldSub(a, b *big.Int) *big.Int { return fieldReduce(new(big.Int).Sub(a, b)) }
+
+func fieldNeg(a *big.Int) *big.Int { return fieldSub(big.NewInt(0), a) }
+
+func fieldInv(a *big.Int) *big.Int { return new(big.Int).ModInverse(a, fieldPrime) }
+
+func fieldExp(a, e *big.Int) *big.Int { return new(big.Int).Exp(a, e, fieldPrime) }
+
+// isNegative is the sign of decaf: whether a is above (p-1)/2
+func isNegative(a *big.Int) bool { return a.Cmp(halfPrime) > 0 }
+
+func littleEndianToInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func intToLittleEndian(a *big.Int, size int) []byte {
+	be := a.Bytes()
+	b := make([]byte, size)
+	for i := range be {
+		b[i] = be[len(be)-1-i]
+	}
+	return b
+}
+
+// limbs splits a reduced field element into the 28-bit limbs of the backend
+func limbs(a *big.Int) [16]uint32 {
+	var l [16]uint32
+	words := new(big.Int).Set(a)
+	mask := big.NewInt(1<<limbBits - 1)
+	for i := range l {
+		l[i] = uint32(new(big.Int).And(words, mask).Uint64())
+		words.Rsh(words, limbBits)
+	}
+	return l
+}
+
+// decafDecode returns the affine coordinates on the backend's curve of a
+// point with the given decaf encoding, as the decaf paper decodes it. The
+// encoding must come from the backend, so it is not checked.
+func decafDecode(b []byte) (x, y *big.Int) {
+	s := littleEndianToInt(b)
+	if s.Sign() == 0 {
+		return big.NewInt(0), big.NewInt(1)
+	}
+
+	// X = 2s, Z = 1 - s^2, u = Z^2 - 4d*s^2
+	ss := fieldMul(s, s)
+	bigX := fieldAdd(s, s)
+	bigZ := fieldSub(big.NewInt(1), ss)
+	u := fieldSub(fieldMul(bigZ, bigZ), fieldMul(fieldMul(big.NewInt(4), twistedD), ss))
+
+	// v = 1/sqrt(u*s^2), negated if u*v is negative, w = v*s*(2 - Z)
+	v := fieldExp(fieldMul(u, ss), invSqrtExp)
+	if isNegative(fieldMul(u, v)) {
+		v = fieldNeg(v)
+	}
+	w := fieldMul(fieldMul(v, s), fieldSub(big.NewInt(2), bigZ))
+
+	// (X : Y : Z) = (X : w*Z : Z)
+	return fieldMul(bigX, fieldInv(bigZ)), w
+}
+
+// EncodeEdDSA implements the point encoding of RFC 8032, section 5.2.2, for
+// Ed448-Goldilocks
+func (c *Ed448Gold) EncodeEdDSA(p Point) []byte {
+	tx, ty := decafDecode(c.PointScalarMul(p, minusQuarter).Encode())
+
+	// phiDual(x, y)
+	xx, yy := fieldMul(tx, tx), fieldMul(ty, ty)
+	x := fieldMul(fieldMul(big.NewInt(2), fieldMul(tx, ty)), fieldInv(fieldAdd(xx, yy)))
+	y := fieldMul(fieldSub(yy, xx), fieldInv(fieldSub(fieldAdd(big.NewInt(2), xx), yy)))
+
+	b := intToLittleEndian(y, edDSAPointSize)
+	b[edDSAPointSize-1] |= byte(x.Bit(0)) << 7
+	return b
+}
+
+// DecodeEdDSA implements the point decoding of RFC 8032, section 5.2.3, for
+// Ed448-Goldilocks, returning an error if the bytes are not a valid encoding
+// of a point. Points that differ by a point of order 4 decode to the same
+// point, so points outside the prime order subgroup do not encode back to
+// the same bytes.
+func (c *Ed448Gold) DecodeEdDSA(bs []byte) (Point, error) {
+	if len(bs) != edDSAPointSize {
+		return nil, errors.New("not a valid point")
+	}
+	sign := uint(bs[edDSAPointSize-1] >> 7)
+	yBytes := append([]byte{}, bs...)
+	yBytes[edDSAPointSize-1] &= 0x7f
+	y := littleEndianToInt(yBytes)
+	if y.Cmp(fieldPrime) >= 0 {
+		return nil, errors.New("not a valid point")
+	}
+
+	// x^2 = (y^2 - 1) / (d*y^2 - 1)
+	yy := fieldMul(y, y)
+	xx := fieldMul(fieldSub(yy, big.NewInt(1)), fieldInv(fieldSub(fieldMul(edwardsD, yy), big.NewInt(1))))
+	x := fieldExp(xx, sqrtExp)
+	if fieldMul(x, x).Cmp(xx) != 0 || (x.Sign() == 0 && sign == 1) {
+		return nil, errors.New("not a valid point")
+	}
+	if x.Bit(0) != sign {
+		x = fieldNeg(x)
+	}
+
+	// phi(-x, y)
+	x = fieldNeg(x)
+	tx := fieldMul(fieldMul(big.NewInt(2), fieldMul(x, y)), fieldInv(fieldSub(yy, xx)))
+	ty := fieldMul(fieldAdd(xx, yy), fieldInv(fieldSub(fieldSub(big.NewInt(2), xx), yy)))
+
+	return Ed448GoldPoint(limbs(tx), limbs(ty), limbs(big.NewInt(1)), limbs(fieldMul(tx, ty))), nil
+}
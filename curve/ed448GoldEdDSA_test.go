@@ -0,0 +1,81 @@
+package curve
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	. "gopkg.in/check.v1"
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// the base point of RFC 8032, section 5.2, and its double
+var (
+	edDSABasePoint = decodeHex("14fa30f25b790898adc8d74e2c13bdfdc4397ce61cffd33ad7c2a0051e9c78874098a36c7373ea4b62c7c9563720768824bcb66e71463f6900")
+	edDSATwice     = decodeHex("ed8693eacdfbeada6ba0cdd1beb2bcbb98302a3a8365650db8c4d88a726de3b7d74d8835a0d76e03b0c2865020d659b38d04d74a63e905ae80")
+)
+
+func (s *Ed448GoldSuite) Test_EdDSAEncoding(c *C) {
+	eg := &Ed448Gold{}
+
+	c.Assert(eg.EncodeEdDSA(eg.G()), DeepEquals, edDSABasePoint)
+	c.Assert(eg.EncodeEdDSA(eg.AddPoints(eg.G(), eg.G())), DeepEquals, edDSATwice)
+
+	negated := append([]byte{}, edDSABasePoint...)
+	negated[edDSAPointSize-1] |= 0x80
+	c.Assert(eg.EncodeEdDSA(eg.SubPoints(eg.Identity(), eg.G())), DeepEquals, negated)
+
+	identity := make([]byte, edDSAPointSize)
+	identity[0] = 0x01
+	c.Assert(eg.EncodeEdDSA(eg.Identity()), DeepEquals, identity)
+
+	for _, b := range [][]byte{edDSABasePoint, edDSATwice, negated, identity} {
+		p, err := eg.DecodeEdDSA(b)
+		c.Assert(err, IsNil)
+		c.Assert(eg.EncodeEdDSA(p), DeepEquals, b)
+	}
+	p, err := eg.DecodeEdDSA(edDSABasePoint)
+	c.Assert(err, IsNil)
+	c.Assert(eg.EqualPoints(p, eg.G()), Equals, true)
+}
+
+func (s *Ed448GoldSuite) Test_EdDSAEncodingRoundTrips(c *C) {
+	eg := &Ed448Gold{}
+
+	for i := 0; i < 8; i++ {
+		k, err := eg.RandScalar(rand.Reader)
+		c.Assert(err, IsNil)
+		p := eg.PrecompScalarMul(k)
+
+		decoded, err := eg.DecodeEdDSA(eg.EncodeEdDSA(p))
+		c.Assert(err, IsNil)
+		c.Assert(eg.EqualPoints(decoded, p), Equals, true)
+	}
+}
+
+func (s *Ed448GoldSuite) Test_DecodeEdDSARejectsInvalidEncodings(c *C) {
+	eg := &Ed448Gold{}
+
+	// y = p is not canonical
+	nonCanonical := intToLittleEndian(fieldPrime, edDSAPointSize)
+
+	// x = 0 cannot be negative
+	negativeZero := make([]byte, edDSAPointSize)
+	negativeZero[0] = 0x01
+	negativeZero[edDSAPointSize-1] = 0x80
+
+	// there is no x for y = 2
+	notOnCurve := make([]byte, edDSAPointSize)
+	notOnCurve[0] = 0x02
+
+	for _, b := range [][]byte{nil, edDSABasePoint[1:], nonCanonical, negativeZero, notOnCurve} {
+		_, err := eg.DecodeEdDSA(b)
+		c.Assert(err, ErrorMatches, "not a valid point")
+	}
+}
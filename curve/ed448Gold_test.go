@@ -0,0 +1,66 @@
+package curve
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+)
+
+type Ed448GoldSuite struct{}
+
+var _ = Suite(&Ed448GoldSuite{})
+
+func (s *Ed448GoldSuite) Test_MultiScalarMul(c *C) {
+	eg := &Ed448Gold{}
+
+	for _, n := range []int{0, 1, 2, 7, 20} {
+		ps := make([]Point, n)
+		ss := make([]Scalar, n)
+		exp := eg.Identity()
+		for i := range ps {
+			k, err := eg.RandScalar(rand.Reader)
+			c.Assert(err, IsNil)
+			ps[i] = eg.PrecompScalarMul(k)
+			ss[i], err = eg.RandScalar(rand.Reader)
+			c.Assert(err, IsNil)
+			exp = eg.AddPoints(exp, eg.PointScalarMul(ps[i], ss[i]))
+		}
+
+		c.Assert(eg.EqualPoints(eg.MultiScalarMul(ps, ss), exp), Equals, true)
+	}
+
+	c.Assert(func() { eg.MultiScalarMul([]Point{eg.G()}, nil) }, Panics, "programmer error: mismatched points and scalars")
+}
+
+func (s *Ed448GoldSuite) Test_HashToPoint(c *C) {
+	eg := &Ed448Gold{}
+
+	p1 := eg.HashToPoint([]byte("label"), []byte{0x01})
+	p2 := eg.HashToPoint([]byte("label"), []byte{0x02})
+
+	c.Assert(eg.IsOnCurve(p1), Equals, true)
+	c.Assert(eg.EqualPoints(p1, eg.Identity()), Equals, false)
+	c.Assert(eg.EqualPoints(p1, eg.HashToPoint([]byte("label"), []byte{0x01})), Equals, true)
+	c.Assert(eg.EqualPoints(p1, p2), Equals, false)
+}
+
+func (s *Ed448GoldSuite) Test_InvertScalar(c *C) {
+	eg := &Ed448Gold{}
+	one := make([]byte, 56)
+	one[0] = 1
+
+	x, _ := eg.RandScalar(rand.Reader)
+	inv := eg.InvertScalar(x)
+
+	c.Assert(eg.Mul(x, inv).Encode(), DeepEquals, one)
+	c.Assert(eg.EqualScalars(eg.InvertScalar(inv), x), Equals, true)
+}
+
+func (s *Ed448GoldSuite) Test_ClearCofactor(c *C) {
+	eg := &Ed448Gold{}
+	var four [scalarSize]byte
+	four[0] = 0x04
+
+	c.Assert(eg.EqualPoints(eg.ClearCofactor(eg.G()), eg.PointScalarMul(eg.G(), Ed448GoldScalar(four[:]))), Equals, true)
+	c.Assert(eg.EqualPoints(eg.ClearCofactor(eg.Identity()), eg.Identity()), Equals, true)
+}
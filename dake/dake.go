@@ -0,0 +1,290 @@
+package dake
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	cs "github.com/twtiger/crypto/cramershoup"
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/dre"
+	"github.com/twtiger/crypto/ringsig"
+)
+
+// SharedSecretSize is the size of the secret both parties hold after an
+// exchange
+const SharedSecretSize = 64
+
+// DAKE is an instance of DAKEZ, the interactive deniable authenticated key
+// exchange of OTRv4. The initiator sends an Identity message, the responder
+// answers with an Auth-R message and the initiator finishes with an Auth-I
+// message, after which both hold the same shared secret.
+//
+// Each party authenticates with a ring signature over its own long-term key,
+// the other party's long-term key and the other party's ephemeral key, so
+// either party could have produced the whole transcript alone. The
+// responder also sends a random point encrypted with DRE to both long-term
+// Cramer-Shoup keys, which is mixed into the shared secret along with the
+// ephemeral ECDH secret.
+//
+// XZDH, the non-interactive variant for responders that are offline, is
+// started with the prekeys the responder left on a prekey server.
+type DAKE struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for DAKEZ
+type Curve interface {
+	curve.BasicCurve
+	curve.SecondGenerator
+	curve.Hasher
+	curve.PointHasher
+	curve.PrecomputedMultiplier
+	curve.PointDoubleScalarMultiplier
+	curve.PointCalculator
+	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
+	curve.PointDecoder
+	curve.StrictPointDecoder
+	curve.EdDSAEncoder
+	curve.ScalarMultiplier
+	curve.ScalarCalculator
+	curve.ScalarAdder
+	curve.ScalarComparer
+	curve.ScalarDecoder
+}
+
+// Profile represents a party's long-term public keys: the ring signature key
+// it authenticates with and the Cramer-Shoup key DRE messages are encrypted
+// to.
+type Profile struct {
+	H  *ringsig.PublicKey
+	CS *cs.PublicKey
+}
+
+// LongTermKeys represents a party's profile and its secret keys.
+type LongTermKeys struct {
+	Profile *Profile
+	H       *ringsig.SecretKey
+	CS      *cs.SecretKey
+}
+
+// IdentityMessage is the first message of an exchange, sent by the
+// initiator with its ephemeral public key Y.
+type IdentityMessage struct {
+	Profile *Profile
+	Y       curve.Point
+}
+
+// AuthRMessage is the second message of an exchange, sent by the responder
+// with its ephemeral public key X, the DRE encryption Gamma of its random
+// point, and the ring signature Sigma of RSig(H_R, {H_I, H_R, Y}, t_R).
+type AuthRMessage struct {
+	Profile *Profile
+	X       curve.Point
+	Gamma   *dre.Message
+	Sigma   *ringsig.Signature
+}
+
+// AuthIMessage is the last message of an exchange, sent by the initiator
+// with the ring signature Sigma of RSig(H_I, {H_R, H_I, X}, t_I).
+type AuthIMessage struct {
+	Sigma *ringsig.Signature
+}
+
+// Initiator holds the state of the party that started an exchange.
+type Initiator struct {
+	dake     *DAKE
+	keys     *LongTermKeys
+	y        curve.Scalar
+	identity *IdentityMessage
+}
+
+// Responder holds the state of the party that answered an exchange.
+type Responder struct {
+	dake     *DAKE
+	profile  *Profile
+	identity *IdentityMessage
+	authR    *AuthRMessage
+	secret   []byte
+}
+
+var (
+	// ErrUnexpectedMessage is returned when a message arrives after the
+	// exchange has finished or failed
+	ErrUnexpectedMessage = errors.New("unexpected DAKE message")
+	// ErrInvalidMessage is returned when a message holds an invalid key or a
+	// signature or encryption that does not verify
+	ErrInvalidMessage = errors.New("invalid DAKE message")
+)
+
+func (d *DAKE) ringSig() *ringsig.RingSig {
+	return &ringsig.RingSig{Curve: d.Curve}
+}
+
+func (d *DAKE) dre() *dre.DRE {
+	return &dre.DRE{Curve: d.Curve}
+}
+
+// GenerateLongTermKeys generates a profile and its secret keys.
+func (d *DAKE) GenerateLongTermKeys(rand io.Reader) (*LongTermKeys, error) {
+	h, err := d.ringSig().GenerateKeys(rand)
+	if err != nil {
+		return nil, err
+	}
+	csKeys, err := (&cs.CramerShoup{Curve: d.Curve}).GenerateKeys(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &LongTermKeys{
+		Profile: &Profile{H: h.Pub, CS: csKeys.Pub},
+		H:       h.Sec,
+		CS:      csKeys.Sec,
+	}, nil
+}
+
+// Encode serializes the profile as H || C || D || H_CS.
+func (p *Profile) Encode() []byte {
+	return curve.Append(p.H.A, p.CS.C, p.CS.D, p.CS.H)
+}
+
+func (d *DAKE) isValidPoint(p curve.Point) bool {
+	return p != nil && d.Curve.IsOnCurve(p) && !d.Curve.EqualPoints(p, d.Curve.Identity())
+}
+
+func (d *DAKE) isValidProfile(p *Profile) bool {
+	return p != nil && p.H != nil && d.isValidPoint(p.H.A) &&
+		(&cs.CramerShoup{Curve: d.Curve}).ValidatePublicKey(p.CS) == nil
+}
+
+// transcript computes t = label || Profile_I || Profile_R || Y || X || Gamma,
+// the message both ring signatures are made on
+func transcript(label string, identity *IdentityMessage, authR *AuthRMessage) []byte {
+	return curve.Append([]byte(label), identity.Profile.Encode(), authR.Profile.Encode(),
+		identity.Y, authR.X, authR.Gamma.Encode())
+}
+
+// sharedSecret computes SHAKE-256("dake_shared_secret" || ECDH || K, 64)
+func sharedSecret(ecdh curve.Point, k []byte) []byte {
+	secret := make([]byte, SharedSecretSize)
+	sha3.ShakeSum256(secret, curve.Append([]byte("dake_shared_secret"), ecdh, k))
+	return secret
+}
+
+// Start begins an exchange as the initiator and returns the Identity
+// message. Errors can result from reading random.
+func (d *DAKE) Start(rand io.Reader, keys *LongTermKeys) (*Initiator, *IdentityMessage, error) {
+	y, err := d.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	identity := &IdentityMessage{
+		Profile: keys.Profile,
+		Y:       d.Curve.PrecompScalarMul(y),
+	}
+	return &Initiator{dake: d, keys: keys, y: y, identity: identity}, identity, nil
+}
+
+// Respond answers an Identity message as the responder with the Auth-R
+// message. The shared secret is only returned by ReceiveAuthI, once the
+// initiator has authenticated. Errors can result from reading random or if
+// the message is invalid.
+func (d *DAKE) Respond(rand io.Reader, keys *LongTermKeys, m *IdentityMessage) (*Responder, *AuthRMessage, error) {
+	if m == nil || !d.isValidProfile(m.Profile) || !d.isValidPoint(m.Y) {
+		return nil, nil, ErrInvalidMessage
+	}
+
+	x, err := d.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	k, err := d.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Gamma = DREnc(Profile_I.CS, Profile_R.CS, G*k)
+	kPoint := d.Curve.PrecompScalarMul(k).Encode()
+	gamma, err := d.dre().Encrypt(kPoint, rand, m.Profile.CS, keys.Profile.CS)
+	if err != nil {
+		return nil, nil, err
+	}
+	authR := &AuthRMessage{
+		Profile: keys.Profile,
+		X:       d.Curve.PrecompScalarMul(x),
+		Gamma:   gamma,
+	}
+
+	// sigma = RSig(H_R, {H_I, H_R, Y}, t_R)
+	authR.Sigma, err = d.ringSig().Sign(rand, keys.H,
+		m.Profile.H, keys.Profile.H, &ringsig.PublicKey{A: m.Y}, transcript("dake_auth_r", m, authR))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Responder{
+		dake:     d,
+		profile:  keys.Profile,
+		identity: m,
+		authR:    authR,
+		secret:   sharedSecret(d.Curve.PointScalarMul(m.Y, x), kPoint),
+	}, authR, nil
+}
+
+// ReceiveAuthR checks the Auth-R message as the initiator and returns the
+// Auth-I message and the shared secret. The exchange ends whether or not the
+// message is valid. Errors can result from reading random, if the exchange
+// has ended, or if the message is invalid.
+func (i *Initiator) ReceiveAuthR(rand io.Reader, m *AuthRMessage) (*AuthIMessage, []byte, error) {
+	d := i.dake
+	if i.y == nil {
+		return nil, nil, ErrUnexpectedMessage
+	}
+	y, identity := i.y, i.identity
+	i.y = nil
+
+	if m == nil || !d.isValidProfile(m.Profile) || !d.isValidPoint(m.X) || m.Gamma == nil {
+		return nil, nil, ErrInvalidMessage
+	}
+	k, err := d.dre().Decrypt(m.Gamma, identity.Profile.CS, m.Profile.CS, i.keys.CS, 1)
+	if err != nil {
+		return nil, nil, ErrInvalidMessage
+	}
+	err = d.ringSig().Verify(identity.Profile.H, m.Profile.H, &ringsig.PublicKey{A: identity.Y},
+		transcript("dake_auth_r", identity, m), m.Sigma)
+	if err != nil {
+		return nil, nil, ErrInvalidMessage
+	}
+
+	// sigma = RSig(H_I, {H_R, H_I, X}, t_I)
+	sigma, err := d.ringSig().Sign(rand, i.keys.H,
+		m.Profile.H, identity.Profile.H, &ringsig.PublicKey{A: m.X}, transcript("dake_auth_i", identity, m))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &AuthIMessage{Sigma: sigma}, sharedSecret(d.Curve.PointScalarMul(m.X, y), k), nil
+}
+
+// ReceiveAuthI checks the Auth-I message as the responder and returns the
+// shared secret. The exchange ends whether or not the message is valid.
+// Errors can result if the exchange has ended or if the message is invalid.
+func (r *Responder) ReceiveAuthI(m *AuthIMessage) ([]byte, error) {
+	d := r.dake
+	if r.secret == nil {
+		return nil, ErrUnexpectedMessage
+	}
+	secret := r.secret
+	r.secret = nil
+
+	if m == nil {
+		return nil, ErrInvalidMessage
+	}
+	err := d.ringSig().Verify(r.profile.H, r.identity.Profile.H, &ringsig.PublicKey{A: r.authR.X},
+		transcript("dake_auth_i", r.identity, r.authR), m.Sigma)
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+	return secret, nil
+}
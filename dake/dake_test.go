@@ -0,0 +1,226 @@
+package dake
+
+import (
+	"crypto/rand"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/ringsig"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type DAKESuite struct{}
+
+var _ = Suite(&DAKESuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	dake      = &DAKE{Curve: testCurve}
+)
+
+func generateKeys(c *C) *LongTermKeys {
+	keys, err := dake.GenerateLongTermKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	return keys
+}
+
+func (s *DAKESuite) Test_ExchangeAgreesOnASharedSecret(c *C) {
+	alice, bob := generateKeys(c), generateKeys(c)
+
+	initiator, identity, err := dake.Start(rand.Reader, alice)
+	c.Assert(err, IsNil)
+	responder, authR, err := dake.Respond(rand.Reader, bob, identity)
+	c.Assert(err, IsNil)
+	authI, initiatorSecret, err := initiator.ReceiveAuthR(rand.Reader, authR)
+	c.Assert(err, IsNil)
+	responderSecret, err := responder.ReceiveAuthI(authI)
+	c.Assert(err, IsNil)
+
+	c.Assert(initiatorSecret, HasLen, SharedSecretSize)
+	c.Assert(initiatorSecret, DeepEquals, responderSecret)
+
+	_, _, err = initiator.ReceiveAuthR(rand.Reader, authR)
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+	_, err = responder.ReceiveAuthI(authI)
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+}
+
+func (s *DAKESuite) Test_EveryExchangeHasANewSecret(c *C) {
+	alice, bob := generateKeys(c), generateKeys(c)
+	secrets := make([][]byte, 2)
+
+	for i := range secrets {
+		initiator, identity, err := dake.Start(rand.Reader, alice)
+		c.Assert(err, IsNil)
+		_, authR, err := dake.Respond(rand.Reader, bob, identity)
+		c.Assert(err, IsNil)
+		_, secrets[i], err = initiator.ReceiveAuthR(rand.Reader, authR)
+		c.Assert(err, IsNil)
+	}
+
+	c.Assert(secrets[0], Not(DeepEquals), secrets[1])
+}
+
+// tamperIdentity runs an exchange where the Identity message is modified in
+// transit and returns the error of the first party to notice
+func tamperIdentity(c *C, tamper func(m *IdentityMessage)) error {
+	alice, bob := generateKeys(c), generateKeys(c)
+	initiator, identity, err := dake.Start(rand.Reader, alice)
+	c.Assert(err, IsNil)
+
+	tampered := *identity
+	tampered.Profile = &Profile{H: identity.Profile.H, CS: identity.Profile.CS}
+	tamper(&tampered)
+
+	_, authR, err := dake.Respond(rand.Reader, bob, &tampered)
+	if err != nil {
+		return err
+	}
+	_, _, err = initiator.ReceiveAuthR(rand.Reader, authR)
+	return err
+}
+
+func (s *DAKESuite) Test_TamperedIdentityMessagesAreRejected(c *C) {
+	mallory := generateKeys(c)
+
+	tamper := []func(m *IdentityMessage){
+		func(m *IdentityMessage) { m.Profile.H = mallory.Profile.H },
+		func(m *IdentityMessage) { m.Profile.CS = mallory.Profile.CS },
+		func(m *IdentityMessage) { m.Y = mallory.Profile.H.A },
+		func(m *IdentityMessage) { m.Y = testCurve.Identity() },
+		func(m *IdentityMessage) { m.Profile.H = &ringsig.PublicKey{A: testCurve.Identity()} },
+		func(m *IdentityMessage) { m.Profile.CS = nil },
+		func(m *IdentityMessage) { m.Profile = nil },
+	}
+	for _, t := range tamper {
+		c.Assert(tamperIdentity(c, t), Equals, ErrInvalidMessage)
+	}
+}
+
+// tamperAuthR runs an exchange where the Auth-R message is modified in
+// transit and returns the error of the initiator
+func tamperAuthR(c *C, tamper func(m *AuthRMessage)) error {
+	alice, bob := generateKeys(c), generateKeys(c)
+	initiator, identity, err := dake.Start(rand.Reader, alice)
+	c.Assert(err, IsNil)
+	_, authR, err := dake.Respond(rand.Reader, bob, identity)
+	c.Assert(err, IsNil)
+
+	tampered := *authR
+	tampered.Profile = &Profile{H: authR.Profile.H, CS: authR.Profile.CS}
+	tamper(&tampered)
+
+	_, _, err = initiator.ReceiveAuthR(rand.Reader, &tampered)
+	return err
+}
+
+func (s *DAKESuite) Test_TamperedAuthRMessagesAreRejected(c *C) {
+	mallory := generateKeys(c)
+	other, err := dake.dre().Encrypt(testCurve.G().Encode(), rand.Reader, mallory.Profile.CS, mallory.Profile.CS)
+	c.Assert(err, IsNil)
+	delta := testHelpers.MustCreateRandScalar()
+
+	tamper := []func(m *AuthRMessage){
+		func(m *AuthRMessage) { m.Profile.H = mallory.Profile.H },
+		func(m *AuthRMessage) { m.Profile.CS = mallory.Profile.CS },
+		func(m *AuthRMessage) { m.Profile = nil },
+		func(m *AuthRMessage) { m.X = mallory.Profile.H.A },
+		func(m *AuthRMessage) { m.X = testCurve.Identity() },
+		func(m *AuthRMessage) { m.Gamma = other },
+		func(m *AuthRMessage) { m.Gamma = nil },
+		func(m *AuthRMessage) {
+			sigma := *m.Sigma
+			sigma.C1 = testCurve.AddScalars(sigma.C1, delta)
+			m.Sigma = &sigma
+		},
+		func(m *AuthRMessage) {
+			sigma := *m.Sigma
+			sigma.R3 = testCurve.AddScalars(sigma.R3, delta)
+			m.Sigma = &sigma
+		},
+		func(m *AuthRMessage) { m.Sigma = nil },
+	}
+	for _, t := range tamper {
+		c.Assert(tamperAuthR(c, t), Equals, ErrInvalidMessage)
+	}
+}
+
+func (s *DAKESuite) Test_TamperedAuthIMessagesAreRejected(c *C) {
+	delta := testHelpers.MustCreateRandScalar()
+
+	tamper := []func(m *AuthIMessage){
+		func(m *AuthIMessage) { m.Sigma.C2 = testCurve.AddScalars(m.Sigma.C2, delta) },
+		func(m *AuthIMessage) { m.Sigma.R1 = testCurve.AddScalars(m.Sigma.R1, delta) },
+		func(m *AuthIMessage) { m.Sigma = nil },
+	}
+	for _, t := range tamper {
+		alice, bob := generateKeys(c), generateKeys(c)
+		initiator, identity, err := dake.Start(rand.Reader, alice)
+		c.Assert(err, IsNil)
+		responder, authR, err := dake.Respond(rand.Reader, bob, identity)
+		c.Assert(err, IsNil)
+		authI, _, err := initiator.ReceiveAuthR(rand.Reader, authR)
+		c.Assert(err, IsNil)
+
+		t(authI)
+		_, err = responder.ReceiveAuthI(authI)
+		c.Assert(err, Equals, ErrInvalidMessage)
+	}
+
+	_, err := (&Responder{dake: dake, secret: []byte{0x01}}).ReceiveAuthI(nil)
+	c.Assert(err, Equals, ErrInvalidMessage)
+}
+
+func (s *DAKESuite) Test_AuthIFromAnotherExchangeIsRejected(c *C) {
+	alice, bob := generateKeys(c), generateKeys(c)
+
+	initiator1, identity1, err := dake.Start(rand.Reader, alice)
+	c.Assert(err, IsNil)
+	_, authR1, err := dake.Respond(rand.Reader, bob, identity1)
+	c.Assert(err, IsNil)
+	authI1, _, err := initiator1.ReceiveAuthR(rand.Reader, authR1)
+	c.Assert(err, IsNil)
+
+	_, identity2, err := dake.Start(rand.Reader, alice)
+	c.Assert(err, IsNil)
+	responder2, _, err := dake.Respond(rand.Reader, bob, identity2)
+	c.Assert(err, IsNil)
+
+	_, err = responder2.ReceiveAuthI(authI1)
+	c.Assert(err, Equals, ErrInvalidMessage)
+}
+
+func (s *DAKESuite) Test_RandomFailures(c *C) {
+	alice, bob := generateKeys(c), generateKeys(c)
+	fixed := testHelpers.FixedRandReader([]byte{0x00})
+
+	_, err := dake.GenerateLongTermKeys(fixed)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, _, err = dake.Start(fixed, alice)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	initiator, identity, err := dake.Start(rand.Reader, alice)
+	c.Assert(err, IsNil)
+	_, _, err = dake.Respond(fixed, bob, identity)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, authR, err := dake.Respond(rand.Reader, bob, identity)
+	c.Assert(err, IsNil)
+	_, _, err = initiator.ReceiveAuthR(fixed, authR)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *DAKESuite) Test_EncodeProfile(c *C) {
+	keys := generateKeys(c)
+
+	encoded := keys.Profile.Encode()
+
+	c.Assert(encoded, HasLen, 4*56)
+	c.Assert(encoded[:56], DeepEquals, keys.Profile.H.A.Encode())
+	c.Assert(encoded[3*56:], DeepEquals, keys.Profile.CS.H.Encode())
+}
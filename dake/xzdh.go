@@ -0,0 +1,211 @@
+package dake
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/ringsig"
+	"github.com/twtiger/crypto/schnorr"
+)
+
+// MACSize is the size of the MAC of a non-interactive Auth message
+const MACSize = 64
+
+// PrekeyProfile holds a responder's shared prekey D, which is used by every
+// exchange until it is replaced, signed with the responder's long-term key.
+type PrekeyProfile struct {
+	D         curve.Point
+	Signature *schnorr.Signature
+}
+
+// PrekeyMessage holds a one-time prekey Y of a responder and the identifier
+// the responder finds its secret by.
+type PrekeyMessage struct {
+	ID uint32
+	Y  curve.Point
+}
+
+// PrekeyEnsemble is what a prekey server hands out to start an exchange with
+// an offline responder: its profile, its prekey profile and one of its
+// prekey messages, which the server must not hand out again.
+type PrekeyEnsemble struct {
+	Profile       *Profile
+	PrekeyProfile *PrekeyProfile
+	Prekey        *PrekeyMessage
+}
+
+// SharedPrekey represents a prekey profile and its secret key.
+type SharedPrekey struct {
+	Profile *PrekeyProfile
+	D       curve.Scalar
+}
+
+// Prekey represents a prekey message and its secret key.
+type Prekey struct {
+	Message *PrekeyMessage
+	Y       curve.Scalar
+}
+
+// NonInteractiveAuthMessage is the only message of an XZDH exchange, sent by
+// the initiator to an offline responder with its ephemeral public key X, the
+// identifier of the prekey message it used, the ring signature Sigma of
+// RSig(H_I, {H_R, H_I, Y}, t) and a MAC of t.
+type NonInteractiveAuthMessage struct {
+	Profile  *Profile
+	X        curve.Point
+	PrekeyID uint32
+	Sigma    *ringsig.Signature
+	MAC      []byte
+}
+
+func (d *DAKE) schnorr() *schnorr.Schnorr {
+	return &schnorr.Schnorr{Curve: d.Curve}
+}
+
+func prekeyProfileMessage(h *ringsig.PublicKey, dPoint curve.Point) []byte {
+	return curve.Append([]byte("xzdh_prekey_profile"), h.A, dPoint)
+}
+
+// GenerateSharedPrekey generates a shared prekey and signs its prekey profile
+// with the long-term keys. Errors can result from reading random.
+func (d *DAKE) GenerateSharedPrekey(rand io.Reader, keys *LongTermKeys) (*SharedPrekey, error) {
+	sec, err := d.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	dPoint := d.Curve.PrecompScalarMul(sec)
+	sig, err := d.schnorr().Sign(rand, &schnorr.SecretKey{X: keys.H.A}, prekeyProfileMessage(keys.Profile.H, dPoint))
+	if err != nil {
+		return nil, err
+	}
+	return &SharedPrekey{
+		Profile: &PrekeyProfile{D: dPoint, Signature: sig},
+		D:       sec,
+	}, nil
+}
+
+// GeneratePrekey generates a one-time prekey with a random identifier.
+// Errors can result from reading random.
+func (d *DAKE) GeneratePrekey(rand io.Reader) (*Prekey, error) {
+	id := make([]byte, 4)
+	if _, err := io.ReadFull(rand, id); err != nil {
+		return nil, errors.New("cannot source enough entropy")
+	}
+	y, err := d.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &Prekey{
+		Message: &PrekeyMessage{
+			ID: uint32(id[0])<<24 | uint32(id[1])<<16 | uint32(id[2])<<8 | uint32(id[3]),
+			Y:  d.Curve.PrecompScalarMul(y),
+		},
+		Y: y,
+	}, nil
+}
+
+// VerifyPrekeyEnsemble checks that every key of the ensemble is valid and
+// that the prekey profile is signed by the long-term key of the profile.
+func (d *DAKE) VerifyPrekeyEnsemble(e *PrekeyEnsemble) error {
+	if e == nil || !d.isValidProfile(e.Profile) || e.PrekeyProfile == nil || e.Prekey == nil ||
+		!d.isValidPoint(e.PrekeyProfile.D) || !d.isValidPoint(e.Prekey.Y) {
+		return ErrInvalidMessage
+	}
+	err := d.schnorr().Verify(&schnorr.PublicKey{Y: e.Profile.H.A},
+		prekeyProfileMessage(e.Profile.H, e.PrekeyProfile.D), e.PrekeyProfile.Signature)
+	if err != nil {
+		return ErrInvalidMessage
+	}
+	return nil
+}
+
+// xzdhKeys derives the MAC key and the shared secret from
+// tmp = SHAKE-256("xzdh_tmp_key" || ECDH(x, Y) || ECDH(x, D), 64)
+func xzdhKeys(ecdhPrekey, ecdhShared curve.Point) (macKey, secret []byte) {
+	tmp := make([]byte, SharedSecretSize)
+	sha3.ShakeSum256(tmp, curve.Append([]byte("xzdh_tmp_key"), ecdhPrekey, ecdhShared))
+
+	macKey = make([]byte, MACSize)
+	sha3.ShakeSum256(macKey, curve.Append([]byte("xzdh_mac_key"), tmp))
+	secret = make([]byte, SharedSecretSize)
+	sha3.ShakeSum256(secret, curve.Append([]byte("xzdh_shared_secret"), tmp))
+	return macKey, secret
+}
+
+// xzdhTranscript computes
+// t = "xzdh_auth" || Profile_R || Profile_I || Y || X || D
+func xzdhTranscript(e *PrekeyEnsemble, initiator *Profile, x curve.Point) []byte {
+	return curve.Append([]byte("xzdh_auth"), e.Profile.Encode(), initiator.Encode(),
+		e.Prekey.Y, x, e.PrekeyProfile.D)
+}
+
+func xzdhMAC(macKey, t []byte) []byte {
+	mac := make([]byte, MACSize)
+	sha3.ShakeSum256(mac, curve.Append([]byte("xzdh_auth_mac"), macKey, t))
+	return mac
+}
+
+// SendNonInteractive starts and finishes an XZDH exchange with the offline
+// responder of the prekey ensemble, returning the non-interactive Auth
+// message to send and the shared secret. The responder's one-time prekey Y
+// is part of the ring, so the responder could have made the signature
+// itself, and only the two parties can compute the MAC, which keeps the
+// exchange deniable. Errors can result from reading random or if the
+// ensemble is invalid.
+func (d *DAKE) SendNonInteractive(rand io.Reader, keys *LongTermKeys, e *PrekeyEnsemble) (*NonInteractiveAuthMessage, []byte, error) {
+	if err := d.VerifyPrekeyEnsemble(e); err != nil {
+		return nil, nil, err
+	}
+	x, err := d.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &NonInteractiveAuthMessage{
+		Profile:  keys.Profile,
+		X:        d.Curve.PrecompScalarMul(x),
+		PrekeyID: e.Prekey.ID,
+	}
+
+	// sigma = RSig(H_I, {H_R, H_I, Y}, t)
+	t := xzdhTranscript(e, keys.Profile, m.X)
+	m.Sigma, err = d.ringSig().Sign(rand, keys.H, e.Profile.H, keys.Profile.H, &ringsig.PublicKey{A: e.Prekey.Y}, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	macKey, secret := xzdhKeys(d.Curve.PointScalarMul(e.Prekey.Y, x), d.Curve.PointScalarMul(e.PrekeyProfile.D, x))
+	m.MAC = xzdhMAC(macKey, t)
+	return m, secret, nil
+}
+
+// ReceiveNonInteractive checks a non-interactive Auth message as the
+// responder, with the shared prekey and the one-time prekey named by the
+// message, and returns the shared secret. The one-time prekey must then be
+// deleted so no other message can use it. An error results if the message
+// is invalid or does not use the given prekey, or if the keys or either
+// prekey are missing.
+func (d *DAKE) ReceiveNonInteractive(keys *LongTermKeys, shared *SharedPrekey, prekey *Prekey, m *NonInteractiveAuthMessage) ([]byte, error) {
+	if keys == nil || keys.Profile == nil || shared == nil || shared.Profile == nil || shared.D == nil ||
+		prekey == nil || prekey.Message == nil || prekey.Y == nil {
+		return nil, ErrInvalidMessage
+	}
+	if m == nil || !d.isValidProfile(m.Profile) || !d.isValidPoint(m.X) || m.PrekeyID != prekey.Message.ID {
+		return nil, ErrInvalidMessage
+	}
+	e := &PrekeyEnsemble{Profile: keys.Profile, PrekeyProfile: shared.Profile, Prekey: prekey.Message}
+	t := xzdhTranscript(e, m.Profile, m.X)
+
+	macKey, secret := xzdhKeys(d.Curve.PointScalarMul(m.X, prekey.Y), d.Curve.PointScalarMul(m.X, shared.D))
+	if subtle.ConstantTimeCompare(m.MAC, xzdhMAC(macKey, t)) != 1 {
+		return nil, ErrInvalidMessage
+	}
+	err := d.ringSig().Verify(keys.Profile.H, m.Profile.H, &ringsig.PublicKey{A: prekey.Message.Y}, t, m.Sigma)
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+	return secret, nil
+}
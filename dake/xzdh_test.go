@@ -0,0 +1,232 @@
+package dake
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/schnorr"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+// prekeyServer is a stand-in for the untrusted server that stores the
+// prekeys of an offline responder and hands each prekey message out once
+type prekeyServer struct {
+	profile       *Profile
+	prekeyProfile *PrekeyProfile
+	prekeys       []*PrekeyMessage
+}
+
+func (s *prekeyServer) ensemble() *PrekeyEnsemble {
+	if len(s.prekeys) == 0 {
+		return nil
+	}
+	prekey := s.prekeys[0]
+	s.prekeys = s.prekeys[1:]
+	return &PrekeyEnsemble{Profile: s.profile, PrekeyProfile: s.prekeyProfile, Prekey: prekey}
+}
+
+// offlineResponder holds the secrets of the prekeys it published
+type offlineResponder struct {
+	keys    *LongTermKeys
+	shared  *SharedPrekey
+	prekeys map[uint32]*Prekey
+}
+
+func publishPrekeys(c *C, keys *LongTermKeys, n int) (*offlineResponder, *prekeyServer) {
+	shared, err := dake.GenerateSharedPrekey(rand.Reader, keys)
+	c.Assert(err, IsNil)
+	responder := &offlineResponder{keys: keys, shared: shared, prekeys: map[uint32]*Prekey{}}
+	server := &prekeyServer{profile: keys.Profile, prekeyProfile: shared.Profile}
+
+	for i := 0; i < n; i++ {
+		prekey, err := dake.GeneratePrekey(rand.Reader)
+		c.Assert(err, IsNil)
+		responder.prekeys[prekey.Message.ID] = prekey
+		server.prekeys = append(server.prekeys, prekey.Message)
+	}
+	return responder, server
+}
+
+// receive finds and deletes the prekey the message names before checking it
+func (r *offlineResponder) receive(m *NonInteractiveAuthMessage) ([]byte, error) {
+	prekey, ok := r.prekeys[m.PrekeyID]
+	if !ok {
+		return nil, ErrUnexpectedMessage
+	}
+	delete(r.prekeys, m.PrekeyID)
+	return dake.ReceiveNonInteractive(r.keys, r.shared, prekey, m)
+}
+
+func (s *DAKESuite) Test_NonInteractiveExchangeAgreesOnASharedSecret(c *C) {
+	alice := generateKeys(c)
+	bob, server := publishPrekeys(c, generateKeys(c), 2)
+
+	ensemble := server.ensemble()
+	c.Assert(dake.VerifyPrekeyEnsemble(ensemble), IsNil)
+	m, aliceSecret, err := dake.SendNonInteractive(rand.Reader, alice, ensemble)
+	c.Assert(err, IsNil)
+	c.Assert(m.MAC, HasLen, MACSize)
+
+	bobSecret, err := bob.receive(m)
+	c.Assert(err, IsNil)
+	c.Assert(aliceSecret, HasLen, SharedSecretSize)
+	c.Assert(bobSecret, DeepEquals, aliceSecret)
+
+	// a prekey is used once, so a replayed message is not accepted
+	_, err = bob.receive(m)
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+
+	// the next ensemble has a new prekey and gives a new secret
+	m2, secret2, err := dake.SendNonInteractive(rand.Reader, alice, server.ensemble())
+	c.Assert(err, IsNil)
+	c.Assert(m2.PrekeyID, Not(Equals), m.PrekeyID)
+	c.Assert(secret2, Not(DeepEquals), aliceSecret)
+	bobSecret2, err := bob.receive(m2)
+	c.Assert(err, IsNil)
+	c.Assert(bobSecret2, DeepEquals, secret2)
+
+	c.Assert(server.ensemble(), IsNil)
+}
+
+func (s *DAKESuite) Test_InvalidPrekeyEnsemblesAreRejected(c *C) {
+	alice, mallory := generateKeys(c), generateKeys(c)
+	_, server := publishPrekeys(c, generateKeys(c), 1)
+	malloryShared, err := dake.GenerateSharedPrekey(rand.Reader, mallory)
+	c.Assert(err, IsNil)
+	ensemble := server.ensemble()
+
+	tamper := []func(e *PrekeyEnsemble){
+		func(e *PrekeyEnsemble) { e.Profile = mallory.Profile },
+		func(e *PrekeyEnsemble) { e.PrekeyProfile = malloryShared.Profile },
+		func(e *PrekeyEnsemble) {
+			e.PrekeyProfile = &PrekeyProfile{D: malloryShared.Profile.D, Signature: e.PrekeyProfile.Signature}
+		},
+		func(e *PrekeyEnsemble) { e.PrekeyProfile = &PrekeyProfile{D: e.PrekeyProfile.D} },
+		func(e *PrekeyEnsemble) { e.Prekey = &PrekeyMessage{ID: e.Prekey.ID, Y: testCurve.Identity()} },
+		func(e *PrekeyEnsemble) { e.Prekey = nil },
+	}
+	for _, t := range tamper {
+		tampered := *ensemble
+		t(&tampered)
+		c.Assert(dake.VerifyPrekeyEnsemble(&tampered), Equals, ErrInvalidMessage)
+		_, _, err := dake.SendNonInteractive(rand.Reader, alice, &tampered)
+		c.Assert(err, Equals, ErrInvalidMessage)
+	}
+	c.Assert(dake.VerifyPrekeyEnsemble(nil), Equals, ErrInvalidMessage)
+}
+
+func (s *DAKESuite) Test_TamperedNonInteractiveAuthMessagesAreRejected(c *C) {
+	alice, mallory := generateKeys(c), generateKeys(c)
+	delta := testHelpers.MustCreateRandScalar()
+
+	tamper := []func(m *NonInteractiveAuthMessage){
+		func(m *NonInteractiveAuthMessage) { m.Profile = mallory.Profile },
+		func(m *NonInteractiveAuthMessage) { m.Profile = &Profile{H: m.Profile.H, CS: mallory.Profile.CS} },
+		func(m *NonInteractiveAuthMessage) { m.Profile = nil },
+		func(m *NonInteractiveAuthMessage) { m.X = mallory.Profile.H.A },
+		func(m *NonInteractiveAuthMessage) { m.X = testCurve.Identity() },
+		func(m *NonInteractiveAuthMessage) {
+			sigma := *m.Sigma
+			sigma.C1 = testCurve.AddScalars(sigma.C1, delta)
+			m.Sigma = &sigma
+		},
+		func(m *NonInteractiveAuthMessage) { m.Sigma = nil },
+		func(m *NonInteractiveAuthMessage) {
+			m.MAC = append([]byte{}, m.MAC...)
+			m.MAC[0] ^= 0x01
+		},
+		func(m *NonInteractiveAuthMessage) { m.MAC = m.MAC[1:] },
+	}
+	for _, t := range tamper {
+		bob, server := publishPrekeys(c, generateKeys(c), 1)
+		m, _, err := dake.SendNonInteractive(rand.Reader, alice, server.ensemble())
+		c.Assert(err, IsNil)
+
+		tampered := *m
+		t(&tampered)
+		_, err = bob.receive(&tampered)
+		c.Assert(err, Equals, ErrInvalidMessage)
+	}
+}
+
+func (s *DAKESuite) Test_ReceiveNonInteractiveChecksThePrekey(c *C) {
+	alice := generateKeys(c)
+	bob, server := publishPrekeys(c, generateKeys(c), 2)
+	m, _, err := dake.SendNonInteractive(rand.Reader, alice, server.ensemble())
+	c.Assert(err, IsNil)
+
+	var other *Prekey
+	for id, prekey := range bob.prekeys {
+		if id != m.PrekeyID {
+			other = prekey
+		}
+	}
+	_, err = dake.ReceiveNonInteractive(bob.keys, bob.shared, other, m)
+	c.Assert(err, Equals, ErrInvalidMessage)
+
+	forged := &Prekey{Message: &PrekeyMessage{ID: m.PrekeyID, Y: other.Message.Y}, Y: other.Y}
+	_, err = dake.ReceiveNonInteractive(bob.keys, bob.shared, forged, m)
+	c.Assert(err, Equals, ErrInvalidMessage)
+
+	newShared, err := dake.GenerateSharedPrekey(rand.Reader, bob.keys)
+	c.Assert(err, IsNil)
+	_, err = dake.ReceiveNonInteractive(bob.keys, newShared, bob.prekeys[m.PrekeyID], m)
+	c.Assert(err, Equals, ErrInvalidMessage)
+}
+
+func (s *DAKESuite) Test_ReceiveNonInteractiveRejectsMissingKeys(c *C) {
+	alice := generateKeys(c)
+	bob, server := publishPrekeys(c, generateKeys(c), 1)
+	m, _, err := dake.SendNonInteractive(rand.Reader, alice, server.ensemble())
+	c.Assert(err, IsNil)
+	prekey := bob.prekeys[m.PrekeyID]
+
+	for _, args := range []struct {
+		keys   *LongTermKeys
+		shared *SharedPrekey
+		prekey *Prekey
+	}{
+		{nil, bob.shared, prekey},
+		{&LongTermKeys{H: bob.keys.H}, bob.shared, prekey},
+		{bob.keys, nil, prekey},
+		{bob.keys, &SharedPrekey{D: bob.shared.D}, prekey},
+		{bob.keys, &SharedPrekey{Profile: bob.shared.Profile}, prekey},
+		{bob.keys, bob.shared, nil},
+		{bob.keys, bob.shared, &Prekey{Y: prekey.Y}},
+		{bob.keys, bob.shared, &Prekey{Message: prekey.Message}},
+	} {
+		_, err = dake.ReceiveNonInteractive(args.keys, args.shared, args.prekey, m)
+		c.Assert(err, Equals, ErrInvalidMessage)
+	}
+
+	_, err = dake.ReceiveNonInteractive(bob.keys, bob.shared, prekey, m)
+	c.Assert(err, IsNil)
+}
+
+func (s *DAKESuite) Test_PrekeyProfileIsSignedByTheLongTermKey(c *C) {
+	keys := generateKeys(c)
+
+	shared, err := dake.GenerateSharedPrekey(rand.Reader, keys)
+
+	c.Assert(err, IsNil)
+	c.Assert(testCurve.EqualPoints(testCurve.PrecompScalarMul(shared.D), shared.Profile.D), Equals, true)
+	err = dake.schnorr().Verify(&schnorr.PublicKey{Y: keys.Profile.H.A},
+		prekeyProfileMessage(keys.Profile.H, shared.Profile.D), shared.Profile.Signature)
+	c.Assert(err, IsNil)
+}
+
+func (s *DAKESuite) Test_PrekeyRandomFailures(c *C) {
+	keys := generateKeys(c)
+	fixed := testHelpers.FixedRandReader([]byte{0x00})
+
+	_, err := dake.GenerateSharedPrekey(fixed, keys)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, err = dake.GeneratePrekey(fixed)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, server := publishPrekeys(c, generateKeys(c), 1)
+	_, _, err = dake.SendNonInteractive(fixed, keys, server.ensemble())
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
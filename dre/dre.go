@@ -8,9 +8,6 @@ import (
 	"github.com/twtiger/crypto/curve"
 )
 
-// TODO: it seems none of the functions and types in this package are exposed, so no-one could actually use them
-// should we expose them if they are valuable to people?
-
 // DRE is an instance of a Dual Receiver Encryption System
 type DRE struct {
 	Curve Curve
@@ -39,11 +36,21 @@ type nIZKProof struct {
 	l, n1, n2 curve.Scalar
 }
 
-type drMessage struct {
+// Message is a message encrypted to two receivers, with a proof that both
+// receivers can decrypt it to the same plaintext.
+type Message struct {
 	cipher drCipher
 	proof  *nIZKProof
 }
 
+// Encode serializes the message as
+// U11 || U21 || E1 || V1 || U12 || U22 || E2 || V2 || l || n1 || n2.
+func (gamma *Message) Encode() []byte {
+	m := &gamma.cipher
+	return curve.Append(m.u11, m.u21, m.e1, m.v1, m.u12, m.u22, m.e2, m.v2,
+		gamma.proof.l, gamma.proof.n1, gamma.proof.n2)
+}
+
 func (d *DRE) isValidPublicKey(pubs ...*cs.PublicKey) error {
 	for _, pub := range pubs {
 		// TODO: not sure if this matters, but this check is not constant time
@@ -159,7 +166,11 @@ func (d *DRE) verifyDRMessage(u1, u2, v curve.Point, alpha curve.Scalar, sec *cs
 	return false, errors.New("cannot decrypt the message")
 }
 
-func (d *DRE) drEnc(message []byte, rand io.Reader, pub1, pub2 *cs.PublicKey) (*drMessage, error) {
+// Encrypt encrypts the given message to both public keys, so that either
+// secret key can decrypt it, and proves that both would decrypt it to the
+// same plaintext. Errors can result from reading random or from an invalid
+// public key.
+func (d *DRE) Encrypt(message []byte, rand io.Reader, pub1, pub2 *cs.PublicKey) (*Message, error) {
 	err := d.isValidPublicKey(pub1, pub2)
 	if err != nil {
 		return nil, err
@@ -174,7 +185,7 @@ func (d *DRE) drEnc(message []byte, rand io.Reader, pub1, pub2 *cs.PublicKey) (*
 		return nil, err
 	}
 
-	gamma := &drMessage{}
+	gamma := &Message{}
 	// u1i = G1*ki, u2i = G2*ki
 	gamma.cipher.u11 = d.Curve.PointScalarMul(d.Curve.G(), k1)
 	gamma.cipher.u21 = d.Curve.PointScalarMul(d.Curve.G2(), k1)
@@ -209,11 +220,18 @@ func (d *DRE) drEnc(message []byte, rand io.Reader, pub1, pub2 *cs.PublicKey) (*
 	return gamma, nil
 }
 
-func (d *DRE) drDec(gamma *drMessage, pub1, pub2 *cs.PublicKey, sec *cs.SecretKey, index int) (message []byte, err error) {
+// Decrypt checks the proof of the message and decrypts it with the secret key
+// of one of its receivers, given by index: 1 for the receiver of pub1 and 2
+// for the receiver of pub2. An error results if a public key is invalid, or
+// if the message does not verify or was not encrypted to the secret key.
+func (d *DRE) Decrypt(gamma *Message, pub1, pub2 *cs.PublicKey, sec *cs.SecretKey, index int) (message []byte, err error) {
 	err = d.isValidPublicKey(pub1, pub2)
 	if err != nil {
 		return nil, err
 	}
+	if gamma == nil || gamma.proof == nil {
+		return nil, errors.New("cannot decrypt the message")
+	}
 
 	// αj = HashToScalar(U1j || U2j || Ej)
 	alpha1 := d.Curve.HashToScalar(gamma.cipher.u11, gamma.cipher.u21, gamma.cipher.e1)
@@ -450,7 +450,7 @@ var (
 		),
 	}
 
-	testDRMessage = &drMessage{
+	testDRMessage = &Message{
 		drCipher{
 			// u11
 			curve.Ed448GoldPoint(
@@ -713,30 +713,30 @@ func (s *DRESuite) SetUpTest(c *C) {
 }
 
 func (s *DRESuite) Test_DREnc(c *C) {
-	m, err := d.drEnc(testMessage, testHelpers.FixedRandReader(randDREData), testPubA, testPubB)
+	m, err := d.Encrypt(testMessage, testHelpers.FixedRandReader(randDREData), testPubA, testPubB)
 	c.Assert(m.cipher, DeepEquals, testDRMessage.cipher)
 	c.Assert(m.proof, DeepEquals, testDRMessage.proof)
 	c.Assert(err, IsNil)
 
-	_, err = d.drEnc(testMessage, testHelpers.FixedRandReader(randDREData), invalidPub, testPubB)
+	_, err = d.Encrypt(testMessage, testHelpers.FixedRandReader(randDREData), invalidPub, testPubB)
 	c.Assert(err, ErrorMatches, ".*not a valid public key")
 
-	_, err = d.drEnc(testMessage, testHelpers.FixedRandReader([]byte{0x00}), testPubA, testPubB)
+	_, err = d.Encrypt(testMessage, testHelpers.FixedRandReader([]byte{0x00}), testPubA, testPubB)
 	c.Assert(err, ErrorMatches, ".*cannot source enough entropy")
 }
 
 func (s *DRESuite) Test_DRDec(c *C) {
-	m, err := d.drDec(testDRMessage, testPubA, testPubB, testSecA, 1)
+	m, err := d.Decrypt(testDRMessage, testPubA, testPubB, testSecA, 1)
 	c.Assert(m, DeepEquals, testMessage)
 	c.Assert(err, IsNil)
 
-	_, err = d.drDec(testDRMessage, invalidPub, testPubB, testSecA, 1)
+	_, err = d.Decrypt(testDRMessage, invalidPub, testPubB, testSecA, 1)
 	c.Assert(err, ErrorMatches, ".*not a valid public key")
 
-	_, err = d.drDec(testDRMessage, testPubA, testPubB, testSecB, 1)
+	_, err = d.Decrypt(testDRMessage, testPubA, testPubB, testSecB, 1)
 	c.Assert(err, ErrorMatches, ".*cannot decrypt the message")
 
-	_, err = d.drDec(testDRMessage, testPubA, testPubB, testSecA, 2)
+	_, err = d.Decrypt(testDRMessage, testPubA, testPubB, testSecA, 2)
 	c.Assert(err, ErrorMatches, ".*cannot decrypt the message")
 }
 
@@ -754,16 +754,32 @@ func (s *DRESuite) Test_DREncryptAndDecrypt(c *C) {
 	keyPairA, err := crsh.GenerateKeys(rand.Reader)
 	keyPairB, err := crsh.GenerateKeys(rand.Reader)
 
-	drMessage, err := d.drEnc(message, rand.Reader, keyPairA.Pub, keyPairB.Pub)
+	drMessage, err := d.Encrypt(message, rand.Reader, keyPairA.Pub, keyPairB.Pub)
 
-	expMessage1, err := d.drDec(drMessage, keyPairA.Pub, keyPairB.Pub, keyPairA.Sec, 1)
+	expMessage1, err := d.Decrypt(drMessage, keyPairA.Pub, keyPairB.Pub, keyPairA.Sec, 1)
 	c.Assert(err, IsNil)
 	c.Assert(expMessage1, DeepEquals, message)
-	expMessage2, err := d.drDec(drMessage, keyPairA.Pub, keyPairB.Pub, keyPairB.Sec, 2)
+	expMessage2, err := d.Decrypt(drMessage, keyPairA.Pub, keyPairB.Pub, keyPairB.Sec, 2)
 	c.Assert(err, IsNil)
 	c.Assert(expMessage2, DeepEquals, message)
 }
 
+func (s *DRESuite) Test_EncodeMessage(c *C) {
+	encoded := testDRMessage.Encode()
+
+	c.Assert(encoded, HasLen, 8*56+3*56)
+	c.Assert(encoded[:56], DeepEquals, testDRMessage.cipher.u11.Encode())
+	c.Assert(encoded[8*56:9*56], DeepEquals, testDRMessage.proof.l.Encode())
+}
+
+func (s *DRESuite) Test_DecryptRejectsMissingMessages(c *C) {
+	_, err := d.Decrypt(nil, testPubA, testPubB, testSecA, 1)
+	c.Assert(err, ErrorMatches, "cannot decrypt the message")
+
+	_, err = d.Decrypt(&Message{cipher: testDRMessage.cipher}, testPubA, testPubB, testSecA, 1)
+	c.Assert(err, ErrorMatches, "cannot decrypt the message")
+}
+
 func (s *DRESuite) Test_GenerationOfNIZKPK(c *C) {
 	alpha1 := curve.Ed448GoldScalar([]byte{
 		0x1c, 0x51, 0x56, 0x90, 0x17, 0x2d, 0x14, 0x41,
@@ -0,0 +1,89 @@
+package ecdh
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// SharedSecretSize is the size of the secret returned by SharedSecret
+const SharedSecretSize = 64
+
+// ECDH is an instance of elliptic curve Diffie-Hellman key agreement
+type ECDH struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for ECDH
+type Curve interface {
+	curve.BasicCurve
+	curve.PrecomputedMultiplier
+	curve.CofactorClearer
+	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
+}
+
+// PublicKey represents an ECDH public key.
+type PublicKey struct {
+	Y curve.Point
+}
+
+// SecretKey represents an ECDH secret key.
+type SecretKey struct {
+	X curve.Scalar
+}
+
+// KeyPair represents an ECDH key pair.
+type KeyPair struct {
+	Pub *PublicKey
+	Sec *SecretKey
+}
+
+var (
+	// ErrInvalidPublicKey is returned when the peer's public key is not a
+	// point of the prime order subgroup other than the identity
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrInvalidSecretKey is returned when the secret key is missing
+	ErrInvalidSecretKey = errors.New("invalid secret key")
+)
+
+// GenerateKey generates an ECDH key pair. Errors can result from reading
+// random.
+func (e *ECDH) GenerateKey(rand io.Reader) (*KeyPair, error) {
+	x, err := e.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		Pub: &PublicKey{Y: e.Curve.PrecompScalarMul(x)},
+		Sec: &SecretKey{X: x},
+	}, nil
+}
+
+// SharedSecret computes the secret shared with the owner of peer as
+// SHAKE-256("ecdh_shared_secret" || (h * Y) * x, 64), where h is the
+// cofactor. Clearing the cofactor keeps a peer from learning bits of the
+// secret key by sending a point outside the prime order subgroup. An error
+// results if the public key is not on the curve or if the result is the
+// identity, which a peer could otherwise use to force a known secret.
+func (e *ECDH) SharedSecret(sec *SecretKey, peer *PublicKey) ([]byte, error) {
+	if sec == nil || sec.X == nil {
+		return nil, ErrInvalidSecretKey
+	}
+	if peer == nil || peer.Y == nil || !e.Curve.IsOnCurve(peer.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	z := e.Curve.PointScalarMul(e.Curve.ClearCofactor(peer.Y), sec.X)
+	if e.Curve.EqualPoints(z, e.Curve.Identity()) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	secret := make([]byte, SharedSecretSize)
+	sha3.ShakeSum256(secret, curve.Append([]byte("ecdh_shared_secret"), z))
+	return secret, nil
+}
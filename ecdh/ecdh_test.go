@@ -0,0 +1,118 @@
+package ecdh
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ECDHSuite struct{}
+
+var _ = Suite(&ECDHSuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	ecdh      = &ECDH{Curve: testCurve}
+)
+
+// pretendCurve is Ed448-Goldilocks with one point treated as off the curve
+// and another treated as having small order, which cannot be built from
+// valid encodings
+type pretendCurve struct {
+	*curve.Ed448Gold
+	offCurve, smallOrder curve.Point
+}
+
+func (c *pretendCurve) IsOnCurve(p curve.Point) bool {
+	return !c.EqualPoints(p, c.offCurve) && c.Ed448Gold.IsOnCurve(p)
+}
+
+func (c *pretendCurve) ClearCofactor(p curve.Point) curve.Point {
+	if c.EqualPoints(p, c.smallOrder) {
+		return c.Identity()
+	}
+	return c.Ed448Gold.ClearCofactor(p)
+}
+
+func generateKey(c *C) *KeyPair {
+	keys, err := ecdh.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	return keys
+}
+
+func (s *ECDHSuite) Test_BothPartiesComputeTheSameSecret(c *C) {
+	alice, bob := generateKey(c), generateKey(c)
+
+	aliceSecret, err := ecdh.SharedSecret(alice.Sec, bob.Pub)
+	c.Assert(err, IsNil)
+	bobSecret, err := ecdh.SharedSecret(bob.Sec, alice.Pub)
+	c.Assert(err, IsNil)
+
+	c.Assert(aliceSecret, HasLen, SharedSecretSize)
+	c.Assert(aliceSecret, DeepEquals, bobSecret)
+}
+
+func (s *ECDHSuite) Test_SecretIsTheHashOfTheClearedPoint(c *C) {
+	alice, bob := generateKey(c), generateKey(c)
+
+	secret, err := ecdh.SharedSecret(alice.Sec, bob.Pub)
+
+	c.Assert(err, IsNil)
+	z := testCurve.PointScalarMul(testCurve.ClearCofactor(bob.Pub.Y), alice.Sec.X)
+	exp := make([]byte, SharedSecretSize)
+	sha3.ShakeSum256(exp, append([]byte("ecdh_shared_secret"), z.Encode()...))
+	c.Assert(secret, DeepEquals, exp)
+	c.Assert(secret, Not(DeepEquals), z.Encode())
+}
+
+func (s *ECDHSuite) Test_DifferentPeersGiveDifferentSecrets(c *C) {
+	alice, bob, carol := generateKey(c), generateKey(c), generateKey(c)
+
+	withBob, err := ecdh.SharedSecret(alice.Sec, bob.Pub)
+	c.Assert(err, IsNil)
+	withCarol, err := ecdh.SharedSecret(alice.Sec, carol.Pub)
+	c.Assert(err, IsNil)
+
+	c.Assert(withBob, Not(DeepEquals), withCarol)
+}
+
+func (s *ECDHSuite) Test_InvalidPublicKeysAreRejected(c *C) {
+	alice, mallory, eve := generateKey(c), generateKey(c), generateKey(c)
+	pretend := &ECDH{Curve: &pretendCurve{testCurve, mallory.Pub.Y, eve.Pub.Y}}
+
+	for _, peer := range []*PublicKey{
+		{Y: testCurve.Identity()},
+		{Y: mallory.Pub.Y},
+		{Y: eve.Pub.Y},
+		{},
+		nil,
+	} {
+		_, err := pretend.SharedSecret(alice.Sec, peer)
+		c.Assert(err, Equals, ErrInvalidPublicKey)
+	}
+}
+
+func (s *ECDHSuite) Test_InvalidSecretKeysAreRejected(c *C) {
+	bob := generateKey(c)
+
+	_, err := ecdh.SharedSecret(nil, bob.Pub)
+	c.Assert(err, Equals, ErrInvalidSecretKey)
+	_, err = ecdh.SharedSecret(&SecretKey{}, bob.Pub)
+	c.Assert(err, Equals, ErrInvalidSecretKey)
+}
+
+func (s *ECDHSuite) Test_GenerateKey(c *C) {
+	keys := generateKey(c)
+
+	c.Assert(testCurve.EqualPoints(keys.Pub.Y, testCurve.PrecompScalarMul(keys.Sec.X)), Equals, true)
+
+	_, err := ecdh.GenerateKey(testHelpers.FixedRandReader([]byte{0x00}))
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
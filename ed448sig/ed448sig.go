@@ -0,0 +1,245 @@
+package ed448sig
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+const (
+	pointSize  = 57
+	scalarSize = 57
+
+	// SeedSize is the size of the seeds private keys are generated from
+	SeedSize = 57
+	// PublicKeySize is the size of a public key
+	PublicKeySize = pointSize
+	// PrivateKeySize is the size of a private key, the seed followed by the
+	// public key
+	PrivateKeySize = SeedSize + PublicKeySize
+	// SignatureSize is the size of a signature
+	SignatureSize = pointSize + scalarSize
+	// MaxContextSize is the size of the longest context string
+	MaxContextSize = 255
+)
+
+// Ed448 is an instance of the Ed448 and Ed448ph signature schemes of RFC 8032.
+// The curve does all point arithmetic and encodes points as RFC 8032 does.
+type Ed448 struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for Ed448 signatures
+type Curve interface {
+	curve.BasicCurve
+	curve.PrecomputedMultiplier
+	curve.PointDoubleScalarMultiplier
+	curve.PointComparer
+	curve.EdDSAEncoder
+	curve.ScalarCalculator
+	curve.ScalarAdder
+	curve.ScalarMultiplier
+	curve.ScalarDecoder
+}
+
+// PublicKey represents an Ed448 public key.
+type PublicKey []byte
+
+// PrivateKey represents an Ed448 private key, the seed followed by the
+// public key.
+type PrivateKey []byte
+
+// Seed returns the seed the private key was generated from.
+func (priv PrivateKey) Seed() []byte {
+	return append([]byte{}, priv[:SeedSize]...)
+}
+
+// Public returns the public key of the private key.
+func (priv PrivateKey) Public() PublicKey {
+	return append(PublicKey{}, priv[SeedSize:]...)
+}
+
+// ErrInvalidSignature is returned when a signature does not verify
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// GenerateKey generates a key pair from a seed read from rand. Errors can
+// result from reading random.
+func (e *Ed448) GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, errors.New("cannot source enough entropy")
+	}
+	priv, err := e.NewKeyFromSeed(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.Public(), priv, nil
+}
+
+// NewKeyFromSeed computes the private key of RFC 8032, section 5.2.5, from a
+// 57-byte seed.
+func (e *Ed448) NewKeyFromSeed(seed []byte) (PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("invalid seed length")
+	}
+	s, _ := expandSeed(seed)
+	a := e.Curve.EncodeEdDSA(e.Curve.PrecompScalarMul(e.reduce(s)))
+	return append(append(PrivateKey{}, seed...), a...), nil
+}
+
+// SecretScalar returns the pruned secret scalar of RFC 8032, section 5.2.5,
+// of which the public key is a multiple of the base point. Ed448 prunes its
+// scalars the way X448 clamps them, so it is also an X448 private key.
+func (priv PrivateKey) SecretScalar() []byte {
+	s, _ := expandSeed(priv.Seed())
+	return s[:SeedSize-1]
+}
+
+// expandSeed hashes the seed into the pruned secret scalar and the prefix
+// used to derive nonces
+func expandSeed(seed []byte) (s, prefix []byte) {
+	h := make([]byte, 2*SeedSize)
+	sha3.ShakeSum256(h, seed)
+
+	s = h[:SeedSize]
+	s[0] &= 0xfc
+	s[SeedSize-1] = 0
+	s[SeedSize-2] |= 0x80
+	return s, h[SeedSize:]
+}
+
+// dom4 returns the domain separation prefix of RFC 8032, section 5.2
+func dom4(prehashed bool, context []byte) []byte {
+	flag := byte(0)
+	if prehashed {
+		flag = 1
+	}
+	return append([]byte("SigEd448"), append([]byte{flag, byte(len(context))}, context...)...)
+}
+
+func prehash(message []byte) []byte {
+	h := make([]byte, 64)
+	sha3.ShakeSum256(h, message)
+	return h
+}
+
+// Sign signs the message with the given private key and context string, as
+// Ed448. The context may be empty. An error results if the private key or the
+// context has the wrong size.
+func (e *Ed448) Sign(priv PrivateKey, message, context []byte) ([]byte, error) {
+	return e.sign(priv, message, context, false)
+}
+
+// SignPh signs the SHAKE-256 hash of the message with the given private key
+// and context string, as Ed448ph. An error results if the private key or the
+// context has the wrong size.
+func (e *Ed448) SignPh(priv PrivateKey, message, context []byte) ([]byte, error) {
+	return e.sign(priv, prehash(message), context, true)
+}
+
+func (e *Ed448) sign(priv PrivateKey, message, context []byte, prehashed bool) ([]byte, error) {
+	if len(priv) != PrivateKeySize {
+		return nil, errors.New("invalid private key length")
+	}
+	if len(context) > MaxContextSize {
+		return nil, errors.New("context too long")
+	}
+	s, prefix := expandSeed(priv[:SeedSize])
+	dom := dom4(prehashed, context)
+
+	// r = SHAKE256(dom4 || prefix || M, 114), R = B*r
+	r := e.hashToScalar(dom, prefix, message)
+	rBytes := e.Curve.EncodeEdDSA(e.Curve.PrecompScalarMul(r))
+
+	// k = SHAKE256(dom4 || R || A || M, 114), S = r + k*s (mod q)
+	k := e.hashToScalar(dom, rBytes, priv[SeedSize:], message)
+	sig := e.Curve.AddScalars(r, e.Curve.Mul(k, e.reduce(s)))
+
+	return append(append(rBytes, sig.Encode()...), 0), nil
+}
+
+// Verify checks an Ed448 signature made by Sign with the same context
+// string, using the cofactored equation of RFC 8032, section 5.2.7.
+func (e *Ed448) Verify(pub PublicKey, message, sig, context []byte) error {
+	return e.verify(pub, message, sig, context, false)
+}
+
+// VerifyPh checks an Ed448ph signature made by SignPh with the same context
+// string.
+func (e *Ed448) VerifyPh(pub PublicKey, message, sig, context []byte) error {
+	return e.verify(pub, prehash(message), sig, context, true)
+}
+
+func (e *Ed448) verify(pub PublicKey, message, sig, context []byte, prehashed bool) error {
+	if len(sig) != SignatureSize || len(context) > MaxContextSize || sig[SignatureSize-1] != 0 {
+		return ErrInvalidSignature
+	}
+	a, err := e.Curve.DecodeEdDSA(pub)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	r, err := e.Curve.DecodeEdDSA(sig[:pointSize])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	s, err := e.Curve.DecodeScalar(sig[pointSize : SignatureSize-1])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	// k = SHAKE256(dom4 || R || A || M, 114)
+	k := e.hashToScalar(dom4(prehashed, context), sig[:pointSize], pub, message)
+
+	// [S]B - [k]A == R, where decoding drops the components of order 4, so
+	// this is the cofactored equation [4][S]B == [4]R + [4][k]A
+	negK := e.Curve.SubScalars(e.smallScalar(nil), k)
+	if !e.Curve.EqualPoints(e.Curve.PointDoubleScalarMul(e.Curve.G(), s, a, negK), r) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (e *Ed448) hashToScalar(items ...[]byte) curve.Scalar {
+	h := sha3.NewShake256()
+	for _, item := range items {
+		h.Write(item)
+	}
+	out := make([]byte, 2*SeedSize)
+	h.Read(out)
+	return e.reduce(out)
+}
+
+// reduceChunk is the size of the pieces a wide integer is split into for
+// reduction. Each piece is below 2^440, so below the group order.
+const reduceChunk = 55
+
+// reduce computes b mod q for a little-endian b of any length, by splitting
+// b into 55-byte pieces and combining them with Horner's method
+func (e *Ed448) reduce(b []byte) curve.Scalar {
+	// shift = 2^440
+	shiftBytes := make([]byte, reduceChunk+1)
+	shiftBytes[reduceChunk] = 1
+	shift := e.smallScalar(shiftBytes)
+
+	start := (len(b) - 1) / reduceChunk * reduceChunk
+	result := e.smallScalar(b[start:])
+	for start -= reduceChunk; start >= 0; start -= reduceChunk {
+		result = e.Curve.AddScalars(e.Curve.Mul(result, shift), e.smallScalar(b[start:start+reduceChunk]))
+	}
+	return result
+}
+
+// smallScalar decodes up to 56 little-endian bytes of a value below the
+// group order as a scalar
+func (e *Ed448) smallScalar(b []byte) curve.Scalar {
+	padded := make([]byte, scalarSize-1)
+	copy(padded, b)
+	s, err := e.Curve.DecodeScalar(padded)
+	if err != nil {
+		panic("programmer error: cannot decode a small scalar")
+	}
+	return s
+}
@@ -0,0 +1,154 @@
+package ed448sig
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type Ed448Suite struct{}
+
+var _ = Suite(&Ed448Suite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	ed448     = &Ed448{Curve: testCurve}
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// test vectors from RFC 8032, section 7.4 and 7.5
+var rfcVectors = []struct {
+	seed, pub, message, context, sig string
+	prehashed                        bool
+}{
+	{
+		seed:    "6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b",
+		pub:     "5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180",
+		message: "",
+		sig:     "533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600",
+	},
+	{
+		seed:    "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+		pub:     "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+		message: "03",
+		sig:     "26b8f91727bd62897af15e41eb43c377efb9c610d48f2335cb0bd0087810f4352541b143c4b981b7e18f62de8ccdf633fc1bf037ab7cd779805e0dbcc0aae1cbcee1afb2e027df36bc04dcecbf154336c19f0af7e0a6472905e799f1953d2a0ff3348ab21aa4adafd1d234441cf807c03a00",
+	},
+	{
+		seed:    "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+		pub:     "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+		message: "03",
+		context: "666f6f",
+		sig:     "d4f8f6131770dd46f40867d6fd5d5055de43541f8c5e35abbcd001b32a89f7d2151f7647f11d8ca2ae279fb842d607217fce6e042f6815ea000c85741de5c8da1144a6a1aba7f96de42505d7a7298524fda538fccbbb754f578c1cad10d54d0d5428407e85dcbc98a49155c13764e66c3c00",
+	},
+	{
+		seed:      "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ef7822e0d5104127dc05d6dbefde69e3ab2cec7c867c6e2c49",
+		pub:       "259b71c19f83ef77a7abd26524cbdb3161b590a48f7d17de3ee0ba9c52beb743c09428a131d6b1b57303d90d8132c276d5ed3d5d01c0f53880",
+		message:   "616263",
+		sig:       "822f6901f7480f3d5f562c592994d9693602875614483256505600bbc281ae381f54d6bce2ea911574932f52a4e6cadd78769375ec3ffd1b801a0d9b3f4030cd433964b6457ea39476511214f97469b57dd32dbc560a9a94d00bff07620464a3ad203df7dc7ce360c3cd3696d9d9fab90f00",
+		prehashed: true,
+	},
+	{
+		seed:      "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ef7822e0d5104127dc05d6dbefde69e3ab2cec7c867c6e2c49",
+		pub:       "259b71c19f83ef77a7abd26524cbdb3161b590a48f7d17de3ee0ba9c52beb743c09428a131d6b1b57303d90d8132c276d5ed3d5d01c0f53880",
+		message:   "616263",
+		context:   "666f6f",
+		sig:       "c32299d46ec8ff02b54540982814dce9a05812f81962b649d528095916a2aa481065b1580423ef927ecf0af5888f90da0f6a9a85ad5dc3f280d91224ba9911a3653d00e484e2ce232521481c8658df304bb7745a73514cdb9bf3e15784ab71284f8d0704a608c54a6b62d97beb511d132100",
+		prehashed: true,
+	},
+}
+
+func (s *Ed448Suite) Test_RFCVectors(c *C) {
+	for _, v := range rfcVectors {
+		priv, err := ed448.NewKeyFromSeed(decodeHex(v.seed))
+		c.Assert(err, IsNil)
+		c.Assert([]byte(priv.Public()), DeepEquals, decodeHex(v.pub))
+		c.Assert(priv.Seed(), DeepEquals, decodeHex(v.seed))
+
+		message, context := decodeHex(v.message), decodeHex(v.context)
+		sign, verify := ed448.Sign, ed448.Verify
+		if v.prehashed {
+			sign, verify = ed448.SignPh, ed448.VerifyPh
+		}
+
+		sig, err := sign(priv, message, context)
+		c.Assert(err, IsNil)
+		c.Assert(sig, DeepEquals, decodeHex(v.sig))
+		c.Assert(verify(priv.Public(), message, sig, context), IsNil)
+	}
+}
+
+func (s *Ed448Suite) Test_GenerateKey(c *C) {
+	pub, priv, err := ed448.GenerateKey(rand.Reader)
+
+	c.Assert(err, IsNil)
+	c.Assert(pub, HasLen, PublicKeySize)
+	c.Assert(priv, HasLen, PrivateKeySize)
+
+	sig, err := ed448.Sign(priv, []byte("message"), nil)
+	c.Assert(err, IsNil)
+	c.Assert(ed448.Verify(pub, []byte("message"), sig, nil), IsNil)
+
+	_, _, err = ed448.GenerateKey(testHelpers.FixedRandReader([]byte{0x00}))
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, err = ed448.NewKeyFromSeed(make([]byte, 32))
+	c.Assert(err, ErrorMatches, "invalid seed length")
+}
+
+func (s *Ed448Suite) Test_VerifyRejectsInvalidSignatures(c *C) {
+	pub, priv, err := ed448.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	otherPub, _, err := ed448.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	message := []byte("message")
+	context := []byte("context")
+	sig, err := ed448.Sign(priv, message, context)
+	c.Assert(err, IsNil)
+
+	c.Assert(ed448.Verify(pub, message, sig, context), IsNil)
+	c.Assert(ed448.Verify(pub, []byte("other"), sig, context), Equals, ErrInvalidSignature)
+	c.Assert(ed448.Verify(pub, message, sig, nil), Equals, ErrInvalidSignature)
+	c.Assert(ed448.Verify(otherPub, message, sig, context), Equals, ErrInvalidSignature)
+	c.Assert(ed448.VerifyPh(pub, message, sig, context), Equals, ErrInvalidSignature)
+	c.Assert(ed448.Verify(pub, message, sig[1:], context), Equals, ErrInvalidSignature)
+
+	flipped := append([]byte{}, sig...)
+	flipped[0] ^= 0x01
+	c.Assert(ed448.Verify(pub, message, flipped, context), Equals, ErrInvalidSignature)
+
+	// S must be below the group order
+	highS := append([]byte{}, sig...)
+	highS[SignatureSize-1] = 0x01
+	c.Assert(ed448.Verify(pub, message, highS, context), Equals, ErrInvalidSignature)
+
+	highS = append([]byte{}, sig...)
+	for i := pointSize; i < SignatureSize-1; i++ {
+		highS[i] = 0xff
+	}
+	c.Assert(ed448.Verify(pub, message, highS, context), Equals, ErrInvalidSignature)
+
+	invalidPub := append(PublicKey{}, pub...)
+	invalidPub[PublicKeySize-1] |= 0x01
+	c.Assert(ed448.Verify(invalidPub, message, sig, context), Equals, ErrInvalidSignature)
+
+	_, err = ed448.Sign(priv, message, make([]byte, MaxContextSize+1))
+	c.Assert(err, ErrorMatches, "context too long")
+	c.Assert(ed448.Verify(pub, message, sig, make([]byte, MaxContextSize+1)), Equals, ErrInvalidSignature)
+
+	_, err = ed448.Sign(priv[1:], message, nil)
+	c.Assert(err, ErrorMatches, "invalid private key length")
+}
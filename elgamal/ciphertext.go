@@ -0,0 +1,84 @@
+package elgamal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// Ciphertext represents an ElGamal ciphertext (c1, c2).
+type Ciphertext struct {
+	C1, C2 curve.Point
+}
+
+// MarshalBinary serializes the ciphertext as c1 || c2.
+func (ct *Ciphertext) MarshalBinary() ([]byte, error) {
+	if ct.C1 == nil || ct.C2 == nil {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return curve.Append(ct.C1, ct.C2), nil
+}
+
+// DecodeCiphertext deserializes a ciphertext serialized by
+// Ciphertext.MarshalBinary. An error results if the length is wrong or if
+// either point is not a valid point on the curve. Either point can be the
+// identity, since homomorphic operations can produce it.
+func (eg *ElGamal) DecodeCiphertext(b []byte) (*Ciphertext, error) {
+	size := len(eg.Curve.G().Encode())
+	if len(b) != 2*size {
+		return nil, errors.New("invalid ciphertext")
+	}
+
+	c1, err := eg.decodeCiphertextPoint(b[:size])
+	if err != nil {
+		return nil, err
+	}
+	c2, err := eg.decodeCiphertextPoint(b[size:])
+	if err != nil {
+		return nil, err
+	}
+	return &Ciphertext{C1: c1, C2: c2}, nil
+}
+
+func (eg *ElGamal) decodeCiphertextPoint(b []byte) (curve.Point, error) {
+	// the curve does not decode the identity, so it is compared by encoding
+	identity := eg.Curve.Identity()
+	if bytes.Equal(b, identity.Encode()) {
+		return identity, nil
+	}
+	p, err := eg.Curve.DecodePointStrict(b)
+	if err != nil || !eg.Curve.IsOnCurve(p) {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return p, nil
+}
+
+func (eg *ElGamal) isValidPoint(p curve.Point) bool {
+	return p != nil && eg.Curve.IsOnCurve(p) && !eg.Curve.EqualPoints(p, eg.Curve.Identity())
+}
+
+// Mul multiplies two ciphertexts component-wise, which on a curve is point
+// addition. The result is a ciphertext of the sum of both plaintext points.
+func (eg *ElGamal) Mul(a, b *Ciphertext) *Ciphertext {
+	return &Ciphertext{
+		C1: eg.Curve.AddPoints(a.C1, b.C1),
+		C2: eg.Curve.AddPoints(a.C2, b.C2),
+	}
+}
+
+// Rerandomize returns a new ciphertext of the same plaintext, which cannot be
+// linked to the original without the secret key. Errors can result from
+// reading random.
+func (eg *ElGamal) Rerandomize(rand io.Reader, pub *PublicKey, ct *Ciphertext) (*Ciphertext, error) {
+	k, err := eg.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	// c1' = c1 + G*k, c2' = c2 + Y*k
+	return &Ciphertext{
+		C1: eg.Curve.AddPoints(ct.C1, eg.Curve.PrecompScalarMul(k)),
+		C2: eg.Curve.AddPoints(ct.C2, eg.Curve.PointScalarMul(pub.Y, k)),
+	}, nil
+}
@@ -0,0 +1,128 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func randomMessage() []byte {
+	return testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar()).Encode()
+}
+
+func (s *EGSuite) Test_CiphertextEncryptionAndDecryption(c *C) {
+	message := randomMessage()
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	ct, err := eg.EncryptCiphertext(rand.Reader, keyPair.Pub, message)
+	c.Assert(err, IsNil)
+
+	c.Assert(err, IsNil)
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, ct), DeepEquals, message)
+	c.Assert(eg.Decrypt(keyPair.Sec, ct.C1, ct.C2), DeepEquals, message)
+
+	_, err = eg.EncryptCiphertext(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, message)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_MarshalAndDecodeCiphertext(c *C) {
+	message := randomMessage()
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	ct, err := eg.EncryptCiphertext(rand.Reader, keyPair.Pub, message)
+	c.Assert(err, IsNil)
+
+	data, err := ct.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, append(ct.C1.Encode(), ct.C2.Encode()...))
+
+	decoded, err := eg.DecodeCiphertext(data)
+	c.Assert(err, IsNil)
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, decoded), DeepEquals, message)
+
+	_, err = eg.DecodeCiphertext(data[1:])
+	c.Assert(err, ErrorMatches, "invalid ciphertext")
+
+	invalid := append([]byte{}, data...)
+	for i := 0; i < 56; i++ {
+		invalid[i] = 0xff
+	}
+	_, err = eg.DecodeCiphertext(invalid)
+	c.Assert(err, ErrorMatches, "invalid ciphertext")
+
+	_, err = (&Ciphertext{C1: ct.C1}).MarshalBinary()
+	c.Assert(err, ErrorMatches, "invalid ciphertext")
+}
+
+func (s *EGSuite) Test_HomomorphicResultWithIdentityC2SurvivesMarshaling(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	a, err := eg.EncryptExp(rand.Reader, keyPair.Pub, 3)
+	c.Assert(err, IsNil)
+	b, err := eg.EncryptExp(rand.Reader, keyPair.Pub, 5)
+	c.Assert(err, IsNil)
+
+	// a ciphertext whose c2 cancels out that of a+b
+	cancel := &Ciphertext{C1: eg.Curve.G(), C2: eg.Add(a, b).C2}
+	ct := eg.Sub(eg.Add(a, b), cancel)
+	c.Assert(eg.Curve.EqualPoints(ct.C2, eg.Curve.Identity()), Equals, true)
+
+	data, err := ct.MarshalBinary()
+	c.Assert(err, IsNil)
+	decoded, err := eg.DecodeCiphertext(data)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.C1.Encode(), DeepEquals, ct.C1.Encode())
+	c.Assert(decoded.C2.Encode(), DeepEquals, ct.C2.Encode())
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, decoded), DeepEquals, eg.DecryptCiphertext(keyPair.Sec, ct))
+}
+
+func (s *EGSuite) Test_SubtractedCiphertextSurvivesMarshaling(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	tally, err := eg.EncryptExp(rand.Reader, keyPair.Pub, 7)
+	c.Assert(err, IsNil)
+
+	ct := eg.Sub(tally, tally)
+	c.Assert(eg.Curve.EqualPoints(ct.C1, eg.Curve.Identity()), Equals, true)
+	c.Assert(eg.Curve.EqualPoints(ct.C2, eg.Curve.Identity()), Equals, true)
+
+	data, err := ct.MarshalBinary()
+	c.Assert(err, IsNil)
+	decoded, err := eg.DecodeCiphertext(data)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.C1.Encode(), DeepEquals, ct.C1.Encode())
+	c.Assert(decoded.C2.Encode(), DeepEquals, ct.C2.Encode())
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, decoded), DeepEquals, eg.Curve.Identity().Encode())
+}
+
+func (s *EGSuite) Test_CiphertextMul(c *C) {
+	m1, m2 := randomMessage(), randomMessage()
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	ct1, err := eg.EncryptCiphertext(rand.Reader, keyPair.Pub, m1)
+	c.Assert(err, IsNil)
+	ct2, err := eg.EncryptCiphertext(rand.Reader, keyPair.Pub, m2)
+	c.Assert(err, IsNil)
+
+	exp := eg.Curve.AddPoints(eg.Curve.DecodePoint(m1), eg.Curve.DecodePoint(m2))
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, eg.Mul(ct1, ct2)), DeepEquals, exp.Encode())
+}
+
+func (s *EGSuite) Test_CiphertextRerandomize(c *C) {
+	message := randomMessage()
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	ct, err := eg.EncryptCiphertext(rand.Reader, keyPair.Pub, message)
+	c.Assert(err, IsNil)
+
+	rct, err := eg.Rerandomize(rand.Reader, keyPair.Pub, ct)
+	c.Assert(err, IsNil)
+	c.Assert(rct.C1.Encode(), Not(DeepEquals), ct.C1.Encode())
+	c.Assert(rct.C2.Encode(), Not(DeepEquals), ct.C2.Encode())
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, rct), DeepEquals, message)
+
+	_, err = eg.Rerandomize(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, ct)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
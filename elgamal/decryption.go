@@ -0,0 +1,44 @@
+package elgamal
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+var decryptionLabel = []byte("elgamal_decryption")
+
+// ProveDecryption decrypts the ciphertext (c1, c2) like Decrypt, and also
+// returns a Chaum-Pedersen proof that log_G(Y) = log_c1(c2 - M), which shows
+// that the plaintext M was decrypted with the secret key matching Y without
+// revealing it. Errors can result from reading random.
+func (eg *ElGamal) ProveDecryption(rand io.Reader, sec *SecretKey, c1, c2 curve.Point) ([]byte, *DLEQProof, error) {
+	// s = c1*x, m = c2 - s
+	s := eg.Curve.PointScalarMul(c1, sec.X)
+	m := eg.Curve.SubPoints(c2, s)
+
+	proof, err := eg.proveDLEQ(rand, decryptionLabel,
+		eg.Curve.G(), eg.Curve.PrecompScalarMul(sec.X), c1, s, sec.X)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Encode(), proof, nil
+}
+
+// VerifyDecryption checks a proof made by ProveDecryption that the message is
+// the plaintext of the ciphertext (c1, c2) under the given public key. It
+// needs no secret key.
+func (eg *ElGamal) VerifyDecryption(pub *PublicKey, c1, c2 curve.Point, message []byte, proof *DLEQProof) error {
+	m, err := eg.Curve.DecodePointStrict(message)
+	if err != nil || !eg.isValidPoint(c1) || c2 == nil || !eg.isValidPoint(pub.Y) {
+		return errors.New("invalid decryption proof")
+	}
+
+	// s = c2 - m
+	s := eg.Curve.SubPoints(c2, m)
+	if !eg.verifyDLEQ(decryptionLabel, eg.Curve.G(), pub.Y, c1, s, proof) {
+		return errors.New("invalid decryption proof")
+	}
+	return nil
+}
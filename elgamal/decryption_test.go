@@ -0,0 +1,43 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *EGSuite) Test_ProveAndVerifyDecryption(c *C) {
+	message := randomMessage()
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c1, c2, err := eg.Encrypt(rand.Reader, keyPair.Pub, message)
+
+	m, proof, err := eg.ProveDecryption(rand.Reader, keyPair.Sec, c1, c2)
+
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, message)
+	c.Assert(m, DeepEquals, eg.Decrypt(keyPair.Sec, c1, c2))
+	c.Assert(eg.VerifyDecryption(keyPair.Pub, c1, c2, m, proof), IsNil)
+
+	_, _, err = eg.ProveDecryption(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Sec, c1, c2)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_VerifyDecryptionRejectsWrongPlaintext(c *C) {
+	message := randomMessage()
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	otherKeyPair, err := eg.GenerateKeys(rand.Reader)
+	c1, c2, err := eg.Encrypt(rand.Reader, keyPair.Pub, message)
+	m, proof, err := eg.ProveDecryption(rand.Reader, keyPair.Sec, c1, c2)
+
+	c.Assert(eg.VerifyDecryption(keyPair.Pub, c1, c2, randomMessage(), proof), ErrorMatches, "invalid decryption proof")
+	c.Assert(eg.VerifyDecryption(otherKeyPair.Pub, c1, c2, m, proof), ErrorMatches, "invalid decryption proof")
+	c.Assert(eg.VerifyDecryption(keyPair.Pub, c2, c1, m, proof), ErrorMatches, "invalid decryption proof")
+	c.Assert(eg.VerifyDecryption(keyPair.Pub, c1, c2, m[1:], proof), ErrorMatches, "invalid decryption proof")
+	c.Assert(eg.VerifyDecryption(keyPair.Pub, c1, c2, m, nil), ErrorMatches, "invalid decryption proof")
+
+	wrong, wrongProof, err := eg.ProveDecryption(rand.Reader, otherKeyPair.Sec, c1, c2)
+	c.Assert(err, IsNil)
+	c.Assert(eg.VerifyDecryption(keyPair.Pub, c1, c2, wrong, wrongProof), ErrorMatches, "invalid decryption proof")
+}
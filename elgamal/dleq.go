@@ -0,0 +1,50 @@
+package elgamal
+
+import (
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// DLEQProof is a non-interactive Chaum-Pedersen proof that two points have the
+// same discrete logarithm to two different bases, without revealing it.
+type DLEQProof struct {
+	C, S curve.Scalar
+}
+
+func (eg *ElGamal) dleqChallenge(label []byte, g1, h1, g2, h2, a1, a2 curve.Point) curve.Scalar {
+	// c = HashToScalar(label || g1 || h1 || g2 || h2 || a1 || a2)
+	return eg.Curve.HashToScalar(label, g1, h1, g2, h2, a1, a2)
+}
+
+// proveDLEQ proves that log_g1(h1) = log_g2(h2) = x. The label separates
+// proofs made for different purposes.
+func (eg *ElGamal) proveDLEQ(rand io.Reader, label []byte, g1, h1, g2, h2 curve.Point, x curve.Scalar) (*DLEQProof, error) {
+	t, err := eg.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// a1 = g1*t, a2 = g2*t
+	a1 := eg.Curve.PointScalarMul(g1, t)
+	a2 := eg.Curve.PointScalarMul(g2, t)
+
+	// s = t - c*x (mod q)
+	c := eg.dleqChallenge(label, g1, h1, g2, h2, a1, a2)
+	s := eg.Curve.SubScalars(t, eg.Curve.Mul(c, x))
+
+	return &DLEQProof{C: c, S: s}, nil
+}
+
+// verifyDLEQ checks a proof made by proveDLEQ with the same label.
+func (eg *ElGamal) verifyDLEQ(label []byte, g1, h1, g2, h2 curve.Point, proof *DLEQProof) bool {
+	if proof == nil || proof.C == nil || proof.S == nil {
+		return false
+	}
+
+	// a1 = g1*s + h1*c, a2 = g2*s + h2*c
+	a1 := eg.Curve.PointDoubleScalarMul(g1, proof.S, h1, proof.C)
+	a2 := eg.Curve.PointDoubleScalarMul(g2, proof.S, h2, proof.C)
+
+	return eg.Curve.EqualScalars(proof.C, eg.dleqChallenge(label, g1, h1, g2, h2, a1, a2))
+}
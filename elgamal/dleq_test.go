@@ -0,0 +1,29 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *EGSuite) Test_DLEQProof(c *C) {
+	label := []byte("test_label")
+	x := testHelpers.MustCreateRandScalar()
+	g2 := testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar())
+	h1 := testCurve.PrecompScalarMul(x)
+	h2 := testCurve.PointScalarMul(g2, x)
+
+	proof, err := eg.proveDLEQ(rand.Reader, label, testCurve.G(), h1, g2, h2, x)
+
+	c.Assert(err, IsNil)
+	c.Assert(eg.verifyDLEQ(label, testCurve.G(), h1, g2, h2, proof), Equals, true)
+	c.Assert(eg.verifyDLEQ([]byte("other_label"), testCurve.G(), h1, g2, h2, proof), Equals, false)
+	c.Assert(eg.verifyDLEQ(label, testCurve.G(), h1, g2, testCurve.AddPoints(h2, g2), proof), Equals, false)
+	c.Assert(eg.verifyDLEQ(label, testCurve.G(), h1, g2, h2, &DLEQProof{C: proof.S, S: proof.C}), Equals, false)
+	c.Assert(eg.verifyDLEQ(label, testCurve.G(), h1, g2, h2, nil), Equals, false)
+
+	_, err = eg.proveDLEQ(testHelpers.FixedRandReader([]byte{0x00}), label, testCurve.G(), h1, g2, h2, x)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
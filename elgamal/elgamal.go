@@ -14,12 +14,27 @@ type ElGamal struct {
 // Curve defines what curve functions are required for the ElGamal Cryptosystem
 type Curve interface {
 	curve.BasicCurve
+	curve.Identifier
 	curve.PrecomputedMultiplier
 	curve.PointCalculator
+	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
 	curve.PointDecoder
+	curve.StrictPointDecoder
+	curve.PointDoubleScalarMultiplier
+	curve.ScalarCalculator
+	curve.ScalarAdder
+	curve.ScalarMultiplier
+	curve.ScalarInverter
+	curve.ScalarComparer
+	curve.ScalarDecoder
+	curve.Hasher
 }
 
-// PublicKey represents an ElGamal public key.
+// PublicKey represents an ElGamal public key. G and Q are fixed by the
+// curve, so they are not serialized; use LoadPublicKey to fill them in or to
+// check them.
 type PublicKey struct {
 	G curve.Point
 	Q curve.Scalar
@@ -64,16 +79,26 @@ func (eg *ElGamal) GenerateKeys(rand io.Reader) (*KeyPair, error) {
 // Encrypt encrypts the given message to the given public key. The result is a
 // pair of integers. Errors can result from reading random.
 func (eg *ElGamal) Encrypt(rand io.Reader, pub *PublicKey, message []byte) (c1, c2 curve.Point, err error) {
-	k, err := eg.Curve.RandLongTermScalar(rand)
+	ct, err := eg.EncryptCiphertext(rand, pub, message)
 	if err != nil {
 		return nil, nil, err
 	}
+	return ct.C1, ct.C2, nil
+}
+
+// EncryptCiphertext encrypts the given message to the given public key, like
+// Encrypt, but returns the result as a Ciphertext.
+func (eg *ElGamal) EncryptCiphertext(rand io.Reader, pub *PublicKey, message []byte) (*Ciphertext, error) {
+	k, err := eg.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
 	// XXX: check the mod
-	c1 = eg.Curve.PrecompScalarMul(k)
+	c1 := eg.Curve.PrecompScalarMul(k)
 	// XXX: expose the s?
 	s := eg.Curve.PointScalarMul(pub.Y, k)
-	c2 = eg.Curve.AddPoints(s, eg.Curve.DecodePoint(message))
-	return
+	c2 := eg.Curve.AddPoints(s, eg.Curve.DecodePoint(message))
+	return &Ciphertext{C1: c1, C2: c2}, nil
 }
 
 // Decrypt takes two integers, resulting from an ElGamal encryption, and
@@ -82,8 +107,15 @@ func (eg *ElGamal) Encrypt(rand io.Reader, pub *PublicKey, message []byte) (c1,
 // oracle and thus, if exposed to an adaptive chosen ciphertext attack, can
 // be used to break the cryptosystem.  See ``Chosen Ciphertext Attacks
 // Against Protocols Based on the RSA Encryption Standard PKCS #1'', Daniel
-// Bleichenbacher, Advances in Cryptology (Crypto '98).
+// Bleichenbacher, Advances in Cryptology (Crypto '98). New code should use
+// Seal and Open instead.
 func (eg *ElGamal) Decrypt(sec *SecretKey, c1, c2 curve.Point) []byte {
 	s := eg.Curve.PointScalarMul(c1, sec.X)
 	return eg.Curve.SubPoints(c2, s).Encode()
 }
+
+// DecryptCiphertext decrypts the given Ciphertext, like Decrypt. The same
+// padding oracle warning applies.
+func (eg *ElGamal) DecryptCiphertext(sec *SecretKey, ct *Ciphertext) []byte {
+	return eg.Decrypt(sec, ct.C1, ct.C2)
+}
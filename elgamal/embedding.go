@@ -0,0 +1,97 @@
+package elgamal
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// An embedded message is encoded as ctr || len || data || padding, where the
+// last byte is always zero so the encoding is less than the field prime. The
+// counter is incremented until the bytes are the encoding of a point.
+const embedOverhead = 3
+
+func (eg *ElGamal) embedCapacity() int {
+	return len(eg.Curve.G().Encode()) - embedOverhead
+}
+
+// EmbedMessage maps up to len(G.Encode()) - 3 bytes of data to a point, from
+// which ExtractMessage recovers them. Points are found by trying successive
+// counters, so the time taken depends on the data.
+func (eg *ElGamal) EmbedMessage(data []byte) (curve.Point, error) {
+	if len(data) > eg.embedCapacity() {
+		return nil, errors.New("message too long to embed")
+	}
+
+	b := make([]byte, len(eg.Curve.G().Encode()))
+	b[1] = byte(len(data))
+	copy(b[2:], data)
+	for ctr := 0; ctr < 256; ctr++ {
+		b[0] = byte(ctr)
+		p, err := eg.Curve.DecodePointStrict(b)
+		if err == nil && eg.isValidPoint(p) && string(p.Encode()) == string(b) {
+			return p, nil
+		}
+	}
+	return nil, errors.New("cannot embed message")
+}
+
+// ExtractMessage recovers the data embedded in a point by EmbedMessage.
+func (eg *ElGamal) ExtractMessage(p curve.Point) ([]byte, error) {
+	b := p.Encode()
+	n := int(b[1])
+	if n > eg.embedCapacity() || b[len(b)-1] != 0 {
+		return nil, errors.New("no message embedded in the point")
+	}
+	for _, pad := range b[2+n:] {
+		if pad != 0 {
+			return nil, errors.New("no message embedded in the point")
+		}
+	}
+	return append([]byte{}, b[2:2+n]...), nil
+}
+
+// EncryptBytes encrypts a message of any length to the given public key, by
+// splitting it into chunks, embedding each chunk in a point, and encrypting
+// each point. The ciphertexts are not authenticated, so they can be dropped or
+// reordered without detection; use Seal if that matters. Errors can result
+// from reading random.
+func (eg *ElGamal) EncryptBytes(rand io.Reader, pub *PublicKey, message []byte) ([]*Ciphertext, error) {
+	size := eg.embedCapacity()
+	var cts []*Ciphertext
+	for start := 0; start == 0 || start < len(message); start += size {
+		end := start + size
+		if end > len(message) {
+			end = len(message)
+		}
+
+		m, err := eg.EmbedMessage(message[start:end])
+		if err != nil {
+			return nil, err
+		}
+		ct, err := eg.EncryptCiphertext(rand, pub, m.Encode())
+		if err != nil {
+			return nil, err
+		}
+		cts = append(cts, ct)
+	}
+	return cts, nil
+}
+
+// DecryptBytes decrypts the ciphertexts created by EncryptBytes and joins the
+// chunks back together. An error results if any ciphertext does not decrypt
+// to an embedded chunk.
+func (eg *ElGamal) DecryptBytes(sec *SecretKey, cts []*Ciphertext) ([]byte, error) {
+	message := []byte{}
+	for _, ct := range cts {
+		// m = c2 - c1*x
+		m := eg.Curve.SubPoints(ct.C2, eg.Curve.PointScalarMul(ct.C1, sec.X))
+		chunk, err := eg.ExtractMessage(m)
+		if err != nil {
+			return nil, err
+		}
+		message = append(message, chunk...)
+	}
+	return message, nil
+}
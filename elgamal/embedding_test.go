@@ -0,0 +1,60 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *EGSuite) Test_EmbedAndExtractMessage(c *C) {
+	for _, data := range [][]byte{{}, []byte("hello"), make([]byte, 53)} {
+		p, err := eg.EmbedMessage(data)
+		c.Assert(err, IsNil)
+		c.Assert(testCurve.IsOnCurve(p), Equals, true)
+
+		extracted, err := eg.ExtractMessage(p)
+		c.Assert(err, IsNil)
+		c.Assert(extracted, DeepEquals, data)
+	}
+
+	_, err := eg.EmbedMessage(make([]byte, 54))
+	c.Assert(err, ErrorMatches, "message too long to embed")
+
+	p := testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar())
+	for p.Encode()[1] <= 53 && p.Encode()[55] == 0 {
+		p = testCurve.AddPoints(p, testCurve.G())
+	}
+	_, err = eg.ExtractMessage(p)
+	c.Assert(err, ErrorMatches, "no message embedded in the point")
+}
+
+func (s *EGSuite) Test_EncryptAndDecryptBytes(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+
+	for n, chunks := range map[int]int{0: 1, 1: 1, 53: 1, 54: 2, 200: 4} {
+		message := make([]byte, n)
+		rand.Read(message)
+
+		cts, err := eg.EncryptBytes(rand.Reader, keyPair.Pub, message)
+		c.Assert(err, IsNil)
+		c.Assert(cts, HasLen, chunks)
+
+		decrypted, err := eg.DecryptBytes(keyPair.Sec, cts)
+		c.Assert(err, IsNil)
+		c.Assert(decrypted, DeepEquals, message)
+	}
+
+	_, err = eg.EncryptBytes(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, []byte("hello"))
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_DecryptBytesWithWrongKey(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	otherKeyPair, err := eg.GenerateKeys(rand.Reader)
+	cts, err := eg.EncryptBytes(rand.Reader, keyPair.Pub, []byte("hello"))
+
+	_, err = eg.DecryptBytes(otherKeyPair.Sec, cts)
+	c.Assert(err, ErrorMatches, "no message embedded in the point")
+}
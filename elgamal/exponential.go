@@ -0,0 +1,143 @@
+package elgamal
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// MaxDLogTableSize is the largest number of points a DLogTable holds, which
+// limits the plaintext range of a table to fewer than MaxDLogTableSize^2
+// values.
+const MaxDLogTableSize = 1 << 20
+
+// DLogTable is a precomputed baby-step table used to decrypt exponential
+// ElGamal ciphertexts whose plaintexts lie in [Min, Max].
+type DLogTable struct {
+	Min, Max int64
+
+	step  int64
+	giant curve.Point
+	baby  map[string]int64
+}
+
+func (eg *ElGamal) scalarSize() int {
+	return len(eg.Curve.Q().Encode())
+}
+
+// scalarFromInt returns v mod q
+func (eg *ElGamal) scalarFromInt(v int64) curve.Scalar {
+	abs := uint64(v)
+	if v < 0 {
+		abs = uint64(-v)
+	}
+
+	b := make([]byte, eg.scalarSize())
+	for i := 0; i < 8; i++ {
+		b[i] = byte(abs >> uint(8*i))
+	}
+	s, err := eg.Curve.DecodeScalar(b)
+	if err != nil {
+		panic("programmer error: cannot decode a small scalar")
+	}
+
+	if v < 0 {
+		zero, _ := eg.Curve.DecodeScalar(make([]byte, eg.scalarSize()))
+		return eg.Curve.SubScalars(zero, s)
+	}
+	return s
+}
+
+// EncryptExp encrypts the integer m as m*G to the given public key, so that
+// ciphertexts can be added together. The result can only be decrypted if m
+// is small, using DecryptExp. Errors can result from reading random.
+func (eg *ElGamal) EncryptExp(rand io.Reader, pub *PublicKey, m int64) (*Ciphertext, error) {
+	k, err := eg.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	// c1 = G*k, c2 = Y*k + G*m
+	return &Ciphertext{
+		C1: eg.Curve.PrecompScalarMul(k),
+		C2: eg.Curve.AddPoints(eg.Curve.PointScalarMul(pub.Y, k), eg.Curve.PrecompScalarMul(eg.scalarFromInt(m))),
+	}, nil
+}
+
+// Add returns a ciphertext of the sum of the plaintexts of a and b.
+func (eg *ElGamal) Add(a, b *Ciphertext) *Ciphertext {
+	return eg.Mul(a, b)
+}
+
+// Sub returns a ciphertext of the difference of the plaintexts of a and b.
+func (eg *ElGamal) Sub(a, b *Ciphertext) *Ciphertext {
+	return &Ciphertext{
+		C1: eg.Curve.SubPoints(a.C1, b.C1),
+		C2: eg.Curve.SubPoints(a.C2, b.C2),
+	}
+}
+
+// ScalarMul returns a ciphertext of the plaintext of a multiplied by k.
+func (eg *ElGamal) ScalarMul(a *Ciphertext, k int64) *Ciphertext {
+	s := eg.scalarFromInt(k)
+	return &Ciphertext{
+		C1: eg.Curve.PointScalarMul(a.C1, s),
+		C2: eg.Curve.PointScalarMul(a.C2, s),
+	}
+}
+
+// NewDLogTable precomputes the table used by DecryptExp to find plaintexts in
+// [min, max]. The table holds about sqrt(max - min) points, and each
+// decryption takes about as many point additions. An error results if the
+// range would need more than MaxDLogTableSize points.
+func (eg *ElGamal) NewDLogTable(min, max int64) (*DLogTable, error) {
+	if max < min {
+		return nil, errors.New("invalid plaintext range")
+	}
+	if uint64(max)-uint64(min) >= MaxDLogTableSize*MaxDLogTableSize {
+		return nil, errors.New("plaintext range too large")
+	}
+
+	step := int64(1)
+	for step*step <= max-min {
+		step++
+	}
+
+	t := &DLogTable{
+		Min:   min,
+		Max:   max,
+		step:  step,
+		giant: eg.Curve.PrecompScalarMul(eg.scalarFromInt(step)),
+		baby:  make(map[string]int64, step),
+	}
+
+	// baby[G*j] = j, for 0 <= j < step
+	p := eg.Curve.PrecompScalarMul(eg.scalarFromInt(0))
+	for j := int64(0); j < step; j++ {
+		t.baby[string(p.Encode())] = j
+		p = eg.Curve.AddPoints(p, eg.Curve.G())
+	}
+	return t, nil
+}
+
+// DecryptExp decrypts a ciphertext created by EncryptExp, or by combining
+// such ciphertexts, using a baby-step giant-step search over the table's
+// range. An error results if the plaintext is not in the table's range. The
+// search takes a time that depends on the plaintext.
+func (eg *ElGamal) DecryptExp(sec *SecretKey, ct *Ciphertext, table *DLogTable) (int64, error) {
+	// p = c2 - c1*x - G*min = G*(m - min)
+	p := eg.Curve.SubPoints(ct.C2, eg.Curve.PointScalarMul(ct.C1, sec.X))
+	p = eg.Curve.SubPoints(p, eg.Curve.PrecompScalarMul(eg.scalarFromInt(table.Min)))
+
+	for i := int64(0); i*table.step <= table.Max-table.Min; i++ {
+		if j, ok := table.baby[string(p.Encode())]; ok {
+			m := table.Min + i*table.step + j
+			if m <= table.Max {
+				return m, nil
+			}
+			break
+		}
+		p = eg.Curve.SubPoints(p, table.giant)
+	}
+	return 0, errors.New("plaintext out of range")
+}
@@ -0,0 +1,81 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"math"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *EGSuite) Test_ExponentialEncryptionAndDecryption(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	table, err := eg.NewDLogTable(-20, 100)
+	c.Assert(err, IsNil)
+
+	for _, m := range []int64{-20, -1, 0, 1, 9, 10, 11, 57, 99, 100} {
+		ct, err := eg.EncryptExp(rand.Reader, keyPair.Pub, m)
+		c.Assert(err, IsNil)
+
+		exp, err := eg.DecryptExp(keyPair.Sec, ct, table)
+		c.Assert(err, IsNil)
+		c.Assert(exp, Equals, m)
+	}
+
+	for _, m := range []int64{-21, 101, 1000} {
+		ct, err := eg.EncryptExp(rand.Reader, keyPair.Pub, m)
+		c.Assert(err, IsNil)
+
+		_, err = eg.DecryptExp(keyPair.Sec, ct, table)
+		c.Assert(err, ErrorMatches, "plaintext out of range")
+	}
+
+	_, err = eg.EncryptExp(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, 1)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_ExponentialHomomorphism(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	table, err := eg.NewDLogTable(0, 1000)
+	c.Assert(err, IsNil)
+
+	tally, err := eg.EncryptExp(rand.Reader, keyPair.Pub, 0)
+	for _, vote := range []int64{1, 0, 1, 1, 0, 1, 1} {
+		ct, err := eg.EncryptExp(rand.Reader, keyPair.Pub, vote)
+		c.Assert(err, IsNil)
+		tally = eg.Add(tally, ct)
+	}
+
+	m, err := eg.DecryptExp(keyPair.Sec, tally, table)
+	c.Assert(err, IsNil)
+	c.Assert(m, Equals, int64(5))
+
+	three, err := eg.EncryptExp(rand.Reader, keyPair.Pub, 3)
+	m, err = eg.DecryptExp(keyPair.Sec, eg.Sub(tally, three), table)
+	c.Assert(err, IsNil)
+	c.Assert(m, Equals, int64(2))
+
+	m, err = eg.DecryptExp(keyPair.Sec, eg.ScalarMul(tally, 100), table)
+	c.Assert(err, IsNil)
+	c.Assert(m, Equals, int64(500))
+
+	_, err = eg.DecryptExp(keyPair.Sec, eg.Sub(three, tally), table)
+	c.Assert(err, ErrorMatches, "plaintext out of range")
+}
+
+func (s *EGSuite) Test_NewDLogTable(c *C) {
+	table, err := eg.NewDLogTable(5, 5)
+	c.Assert(err, IsNil)
+	c.Assert(table.Min, Equals, int64(5))
+	c.Assert(table.Max, Equals, int64(5))
+
+	_, err = eg.NewDLogTable(5, 4)
+	c.Assert(err, ErrorMatches, "invalid plaintext range")
+
+	_, err = eg.NewDLogTable(0, MaxDLogTableSize*MaxDLogTableSize)
+	c.Assert(err, ErrorMatches, "plaintext range too large")
+
+	_, err = eg.NewDLogTable(math.MinInt64, math.MaxInt64)
+	c.Assert(err, ErrorMatches, "plaintext range too large")
+}
@@ -0,0 +1,106 @@
+package elgamal
+
+import (
+	"errors"
+	"io"
+)
+
+// PETShare is one trustee's contribution to a plaintext equivalence test,
+// the quotient of the two ciphertexts raised to a secret random exponent z,
+// with a Chaum-Pedersen proof that both points were raised to the same z.
+type PETShare struct {
+	Blinded *Ciphertext
+	Proof   *DLEQProof
+}
+
+var petLabel = []byte("elgamal_plaintext_equivalence")
+
+// petQuotient returns a ciphertext of the difference of the plaintexts of a
+// and b, which is the identity if and only if they are equal
+func (eg *ElGamal) petQuotient(a, b *Ciphertext) (*Ciphertext, error) {
+	q := eg.Sub(a, b)
+	if !eg.isValidPoint(q.C1) || !eg.isValidPoint(q.C2) {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return q, nil
+}
+
+// BlindForPET computes a trustee's share of a plaintext equivalence test of
+// the ciphertexts a and b, which must be encrypted to the same key. Errors
+// can result from reading random or if the ciphertexts are invalid.
+//
+// The test runs as follows: every trustee publishes a share from
+// BlindForPET, the shares are combined with CombinePETShares, the trustees
+// holding key shares decrypt the result with PartialDecrypt, and PETResult
+// combines the partial decryptions.
+func (eg *ElGamal) BlindForPET(rand io.Reader, a, b *Ciphertext) (*PETShare, error) {
+	q, err := eg.petQuotient(a, b)
+	if err != nil {
+		return nil, err
+	}
+	z, err := eg.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// c1' = c1*z, c2' = c2*z
+	blinded := &Ciphertext{
+		C1: eg.Curve.PointScalarMul(q.C1, z),
+		C2: eg.Curve.PointScalarMul(q.C2, z),
+	}
+	proof, err := eg.proveDLEQ(rand, petLabel, q.C1, blinded.C1, q.C2, blinded.C2, z)
+	if err != nil {
+		return nil, err
+	}
+	return &PETShare{Blinded: blinded, Proof: proof}, nil
+}
+
+// VerifyPETShare checks that a share was computed by blinding the quotient
+// of the ciphertexts a and b.
+func (eg *ElGamal) VerifyPETShare(a, b *Ciphertext, share *PETShare) error {
+	q, err := eg.petQuotient(a, b)
+	if err != nil {
+		return err
+	}
+	if share == nil || share.Blinded == nil ||
+		!eg.isValidPoint(share.Blinded.C1) || !eg.isValidPoint(share.Blinded.C2) ||
+		!eg.verifyDLEQ(petLabel, q.C1, share.Blinded.C1, q.C2, share.Blinded.C2, share.Proof) {
+		return errors.New("invalid plaintext equivalence share")
+	}
+	return nil
+}
+
+// CombinePETShares checks every trustee's share and combines them into a
+// ciphertext of the difference of the plaintexts of a and b, blinded by the
+// sum of the trustees' exponents. An error results if any share is invalid,
+// or if the exponents cancel out.
+func (eg *ElGamal) CombinePETShares(a, b *Ciphertext, shares []*PETShare) (*Ciphertext, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("invalid plaintext equivalence share")
+	}
+	for _, share := range shares {
+		if err := eg.VerifyPETShare(a, b, share); err != nil {
+			return nil, err
+		}
+	}
+
+	blinded := shares[0].Blinded
+	for _, share := range shares[1:] {
+		blinded = eg.Mul(blinded, share.Blinded)
+	}
+	if !eg.isValidPoint(blinded.C1) {
+		return nil, errors.New("invalid plaintext equivalence share")
+	}
+	return blinded, nil
+}
+
+// PETResult combines the trustees' partial decryptions of the ciphertext
+// returned by CombinePETShares, and reports whether the two ciphertexts
+// encrypt the same message. Nothing else about the messages is revealed.
+func (eg *ElGamal) PETResult(tpub *ThresholdPublicKey, blinded *Ciphertext, pds []*PartialDecryption) (bool, error) {
+	m, err := eg.CombinePartialDecryptions(tpub, blinded, pds)
+	if err != nil {
+		return false, err
+	}
+	return string(m) == string(eg.Curve.Identity().Encode()), nil
+}
@@ -0,0 +1,84 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func runPET(c *C, tpub *ThresholdPublicKey, shares []*KeyShare, a, b *Ciphertext) (bool, error) {
+	petShares := make([]*PETShare, len(shares))
+	for i := range shares {
+		share, err := eg.BlindForPET(rand.Reader, a, b)
+		if err != nil {
+			return false, err
+		}
+		petShares[i] = share
+	}
+	blinded, err := eg.CombinePETShares(a, b, petShares)
+	if err != nil {
+		return false, err
+	}
+	return eg.PETResult(tpub, blinded, partialDecryptions(c, blinded, shares))
+}
+
+func (s *EGSuite) Test_PlaintextEquivalenceTest(c *C) {
+	message := randomMessage()
+	tpub, shares, err := eg.GenerateThresholdKeys(rand.Reader, 2, 3)
+	c.Assert(err, IsNil)
+	a, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, message)
+	c.Assert(err, IsNil)
+	b, err := eg.Rerandomize(rand.Reader, tpub.PublicKey, a)
+	c.Assert(err, IsNil)
+	other, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, randomMessage())
+	c.Assert(err, IsNil)
+
+	equal, err := runPET(c, tpub, shares, a, b)
+	c.Assert(err, IsNil)
+	c.Assert(equal, Equals, true)
+
+	equal, err = runPET(c, tpub, shares[1:], a, b)
+	c.Assert(err, IsNil)
+	c.Assert(equal, Equals, true)
+
+	equal, err = runPET(c, tpub, shares, a, other)
+	c.Assert(err, IsNil)
+	c.Assert(equal, Equals, false)
+
+	_, err = runPET(c, tpub, shares, a, a)
+	c.Assert(err, ErrorMatches, "invalid ciphertext")
+}
+
+func (s *EGSuite) Test_PETRejectsInvalidShares(c *C) {
+	tpub, _, err := eg.GenerateThresholdKeys(rand.Reader, 2, 3)
+	c.Assert(err, IsNil)
+	a, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, randomMessage())
+	c.Assert(err, IsNil)
+	b, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, randomMessage())
+	c.Assert(err, IsNil)
+	other, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, randomMessage())
+	c.Assert(err, IsNil)
+
+	share, err := eg.BlindForPET(rand.Reader, a, b)
+	c.Assert(err, IsNil)
+	c.Assert(eg.VerifyPETShare(a, b, share), IsNil)
+	c.Assert(eg.VerifyPETShare(a, other, share), ErrorMatches, "invalid plaintext equivalence share")
+
+	forged := &PETShare{
+		Blinded: &Ciphertext{C1: share.Blinded.C1, C2: testCurve.AddPoints(share.Blinded.C2, testCurve.G())},
+		Proof:   share.Proof,
+	}
+	_, err = eg.CombinePETShares(a, b, []*PETShare{share, forged})
+	c.Assert(err, ErrorMatches, "invalid plaintext equivalence share")
+
+	_, err = eg.CombinePETShares(a, b, nil)
+	c.Assert(err, ErrorMatches, "invalid plaintext equivalence share")
+
+	_, err = eg.BlindForPET(rand.Reader, a, a)
+	c.Assert(err, ErrorMatches, "invalid ciphertext")
+
+	_, err = eg.BlindForPET(testHelpers.FixedRandReader([]byte{0x00}), a, b)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
@@ -0,0 +1,66 @@
+package elgamal
+
+import (
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// ProxyCiphertext represents a ciphertext of the proxy re-encryption scheme of
+// Blaze, Bleumer and Strauss (BBS98), c1 = Y*k, c2 = M + G*k. Unlike a
+// Ciphertext, it can be transformed by a proxy holding a ReKey into a
+// ciphertext of the same message for another key, without the proxy learning
+// the message.
+type ProxyCiphertext struct {
+	C1, C2 curve.Point
+}
+
+// ReKey represents a re-encryption key from one key pair to another, the
+// scalar b/a for secret keys a and b. The scheme is bidirectional: the
+// inverse of a ReKey re-encrypts the other way, and a proxy holding a ReKey
+// together with either secret key can recover the other.
+type ReKey struct {
+	K curve.Scalar
+}
+
+// EncryptForProxy encrypts the given message to the given public key, so that
+// it can later be re-encrypted for another key by ReEncrypt. Errors can result
+// from reading random.
+func (eg *ElGamal) EncryptForProxy(rand io.Reader, pub *PublicKey, message []byte) (*ProxyCiphertext, error) {
+	k, err := eg.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	// c1 = Y*k, c2 = M + G*k
+	return &ProxyCiphertext{
+		C1: eg.Curve.PointScalarMul(pub.Y, k),
+		C2: eg.Curve.AddPoints(eg.Curve.DecodePoint(message), eg.Curve.PrecompScalarMul(k)),
+	}, nil
+}
+
+// ReKeyGen computes the re-encryption key that transforms ciphertexts for
+// from into ciphertexts for to. It needs both secret keys, so it is computed
+// by the two key holders together, or by a party trusted by both.
+func (eg *ElGamal) ReKeyGen(from, to *SecretKey) *ReKey {
+	// rk = b * a^-1
+	return &ReKey{K: eg.Curve.Mul(to.X, eg.Curve.InvertScalar(from.X))}
+}
+
+// ReEncrypt transforms a ciphertext for one key into a ciphertext of the same
+// message for the key the ReKey was generated for. It needs no secret key and
+// reveals nothing about the message.
+func (eg *ElGamal) ReEncrypt(rk *ReKey, ct *ProxyCiphertext) *ProxyCiphertext {
+	// c1' = c1*rk = G*b*k
+	return &ProxyCiphertext{
+		C1: eg.Curve.PointScalarMul(ct.C1, rk.K),
+		C2: ct.C2,
+	}
+}
+
+// DecryptProxy decrypts a ciphertext created by EncryptForProxy or
+// ReEncrypt. The same padding oracle warning as for Decrypt applies.
+func (eg *ElGamal) DecryptProxy(sec *SecretKey, ct *ProxyCiphertext) []byte {
+	// m = c2 - c1*x^-1
+	s := eg.Curve.PointScalarMul(ct.C1, eg.Curve.InvertScalar(sec.X))
+	return eg.Curve.SubPoints(ct.C2, s).Encode()
+}
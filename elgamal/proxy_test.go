@@ -0,0 +1,59 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *EGSuite) Test_ProxyReEncryption(c *C) {
+	message := randomMessage()
+	alice, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	bob, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	carol, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	ct, err := eg.EncryptForProxy(rand.Reader, alice.Pub, message)
+
+	c.Assert(err, IsNil)
+	c.Assert(eg.DecryptProxy(alice.Sec, ct), DeepEquals, message)
+	c.Assert(eg.DecryptProxy(bob.Sec, ct), Not(DeepEquals), message)
+
+	toBob := eg.ReEncrypt(eg.ReKeyGen(alice.Sec, bob.Sec), ct)
+
+	c.Assert(eg.DecryptProxy(bob.Sec, toBob), DeepEquals, message)
+	c.Assert(eg.DecryptProxy(alice.Sec, toBob), Not(DeepEquals), message)
+	c.Assert(testCurve.EqualPoints(toBob.C2, ct.C2), Equals, true)
+
+	toCarol := eg.ReEncrypt(eg.ReKeyGen(bob.Sec, carol.Sec), toBob)
+
+	c.Assert(eg.DecryptProxy(carol.Sec, toCarol), DeepEquals, message)
+
+	back := eg.ReEncrypt(eg.ReKeyGen(carol.Sec, alice.Sec), toCarol)
+
+	c.Assert(eg.DecryptProxy(alice.Sec, back), DeepEquals, message)
+	c.Assert(testCurve.EqualPoints(back.C1, ct.C1), Equals, true)
+
+	_, err = eg.EncryptForProxy(testHelpers.FixedRandReader([]byte{0x00}), alice.Pub, message)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_ReKeyWithWrongSourceKey(c *C) {
+	message := randomMessage()
+	alice, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	bob, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	carol, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	ct, err := eg.EncryptForProxy(rand.Reader, alice.Pub, message)
+	c.Assert(err, IsNil)
+
+	wrong := eg.ReEncrypt(eg.ReKeyGen(carol.Sec, bob.Sec), ct)
+	c.Assert(eg.DecryptProxy(bob.Sec, wrong), Not(DeepEquals), message)
+}
@@ -0,0 +1,62 @@
+package elgamal
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// LoadPublicKey checks a public key against the curve and returns a copy with
+// G and Q filled in from the curve. Keys created before G and Q were taken
+// from the curve still carry them, so they are accepted only if they agree
+// with the curve. An error results if they disagree or if Y is not a valid
+// point.
+func (eg *ElGamal) LoadPublicKey(pub *PublicKey) (*PublicKey, error) {
+	if pub == nil || !eg.isValidPoint(pub.Y) {
+		return nil, errors.New("invalid public key")
+	}
+	if pub.G != nil && !eg.Curve.EqualPoints(pub.G, eg.Curve.G()) {
+		return nil, errors.New("public key does not match the curve")
+	}
+	if pub.Q != nil && !bytes.Equal(pub.Q.Encode(), eg.Curve.Q().Encode()) {
+		return nil, errors.New("public key does not match the curve")
+	}
+	return eg.publicKey(pub.Y), nil
+}
+
+func (eg *ElGamal) publicKey(y curve.Point) *PublicKey {
+	return &PublicKey{
+		G: eg.Curve.G(),
+		Q: eg.Curve.Q(),
+		Y: y,
+	}
+}
+
+// MarshalPublicKey serializes the public key as the curve identifier
+// followed by Y. G and Q are left out, since they are fixed by the curve. An
+// error results if the key does not match the curve.
+func (eg *ElGamal) MarshalPublicKey(pub *PublicKey) ([]byte, error) {
+	pub, err := eg.LoadPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{eg.Curve.ID()}, pub.Y.Encode()...), nil
+}
+
+// UnmarshalPublicKey deserializes a public key serialized by
+// MarshalPublicKey, filling in G and Q from the curve. An error results if the
+// key was serialized for another curve or if Y is not a valid point.
+func (eg *ElGamal) UnmarshalPublicKey(data []byte) (*PublicKey, error) {
+	if len(data) != 1+len(eg.Curve.G().Encode()) {
+		return nil, errors.New("invalid public key")
+	}
+	if data[0] != eg.Curve.ID() {
+		return nil, errors.New("public key does not match the curve")
+	}
+	y, err := eg.Curve.DecodePointStrict(data[1:])
+	if err != nil {
+		return nil, errors.New("invalid public key")
+	}
+	return eg.LoadPublicKey(&PublicKey{Y: y})
+}
@@ -0,0 +1,69 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+func (s *EGSuite) Test_MarshalAndUnmarshalPublicKey(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+
+	data, err := eg.MarshalPublicKey(keyPair.Pub)
+
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, append([]byte{curve.Ed448GoldID}, keyPair.Pub.Y.Encode()...))
+
+	pub, err := eg.UnmarshalPublicKey(data)
+
+	c.Assert(err, IsNil)
+	c.Assert(testCurve.EqualPoints(pub.Y, keyPair.Pub.Y), Equals, true)
+	c.Assert(pub.G, DeepEquals, testCurve.G())
+	c.Assert(pub.Q, DeepEquals, testCurve.Q())
+
+	message := randomMessage()
+	ct, err := eg.EncryptCiphertext(rand.Reader, pub, message)
+	c.Assert(eg.DecryptCiphertext(keyPair.Sec, ct), DeepEquals, message)
+
+	_, err = eg.UnmarshalPublicKey(data[1:])
+	c.Assert(err, ErrorMatches, "invalid public key")
+
+	otherCurve := append([]byte{0x02}, data[1:]...)
+	_, err = eg.UnmarshalPublicKey(otherCurve)
+	c.Assert(err, ErrorMatches, "public key does not match the curve")
+
+	identity := append([]byte{curve.Ed448GoldID}, testCurve.Identity().Encode()...)
+	_, err = eg.UnmarshalPublicKey(identity)
+	c.Assert(err, ErrorMatches, "invalid public key")
+}
+
+func (s *EGSuite) Test_LoadPublicKey(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+
+	pub, err := eg.LoadPublicKey(keyPair.Pub)
+	c.Assert(err, IsNil)
+	c.Assert(pub, DeepEquals, keyPair.Pub)
+
+	pub, err = eg.LoadPublicKey(&PublicKey{Y: keyPair.Pub.Y})
+	c.Assert(err, IsNil)
+	c.Assert(pub, DeepEquals, keyPair.Pub)
+
+	_, err = eg.LoadPublicKey(&PublicKey{G: testCurve.G2(), Q: testCurve.Q(), Y: keyPair.Pub.Y})
+	c.Assert(err, ErrorMatches, "public key does not match the curve")
+
+	_, err = eg.LoadPublicKey(&PublicKey{G: testCurve.G(), Q: randomScalar(), Y: keyPair.Pub.Y})
+	c.Assert(err, ErrorMatches, "public key does not match the curve")
+
+	_, err = eg.LoadPublicKey(&PublicKey{})
+	c.Assert(err, ErrorMatches, "invalid public key")
+
+	_, err = eg.MarshalPublicKey(&PublicKey{G: testCurve.G2(), Y: keyPair.Pub.Y})
+	c.Assert(err, ErrorMatches, "public key does not match the curve")
+}
+
+func randomScalar() curve.Scalar {
+	s, _ := testCurve.RandScalar(rand.Reader)
+	return s
+}
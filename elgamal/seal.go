@@ -0,0 +1,85 @@
+package elgamal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+const (
+	sealKeySize   = 32
+	sealNonceSize = 12
+)
+
+// sealKey derives the AES-256-GCM key and nonce from the shared point, as
+// SHAKE-256("elgamal_seal" || R || Y || S). The key is never reused, so the
+// nonce can be derived along with it.
+func sealKey(r, y, s curve.Point) (cipher.AEAD, []byte, error) {
+	out := make([]byte, sealKeySize+sealNonceSize)
+	sha3.ShakeSum256(out, curve.Append([]byte("elgamal_seal"), r, y, s))
+
+	block, err := aes.NewCipher(out[:sealKeySize])
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, out[sealKeySize:], nil
+}
+
+// Seal encrypts and authenticates a plaintext of any length to the given
+// public key, and authenticates the additional data, which is not encrypted.
+// The result is R || AES-256-GCM(plaintext), where R = G*k and the key is
+// derived from Y*k with SHAKE-256. Unlike Encrypt, the result cannot be
+// modified without detection, so this is the recommended API for new code.
+// Errors can result from reading random.
+func (eg *ElGamal) Seal(rand io.Reader, pub *PublicKey, plaintext, aad []byte) ([]byte, error) {
+	k, err := eg.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// r = G*k, s = Y*k
+	r := eg.Curve.PrecompScalarMul(k)
+	s := eg.Curve.PointScalarMul(pub.Y, k)
+
+	aead, nonce, err := sealKey(r, pub.Y, s)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(r.Encode(), nonce, plaintext, aad), nil
+}
+
+// Open decrypts and authenticates a ciphertext created by Seal with the same
+// additional data. An error results if the ciphertext or the additional data
+// were modified, or if the ciphertext was sealed to another key.
+func (eg *ElGamal) Open(sec *SecretKey, ciphertext, aad []byte) ([]byte, error) {
+	size := len(eg.Curve.G().Encode())
+	if len(ciphertext) < size {
+		return nil, errors.New("cannot open the message")
+	}
+	r, err := eg.Curve.DecodePointStrict(ciphertext[:size])
+	if err != nil || !eg.isValidPoint(r) {
+		return nil, errors.New("cannot open the message")
+	}
+
+	// s = r*x
+	s := eg.Curve.PointScalarMul(r, sec.X)
+
+	aead, nonce, err := sealKey(r, eg.Curve.PrecompScalarMul(sec.X), s)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext[size:], aad)
+	if err != nil {
+		return nil, errors.New("cannot open the message")
+	}
+	return plaintext, nil
+}
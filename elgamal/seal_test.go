@@ -0,0 +1,51 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *EGSuite) Test_SealAndOpen(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	aad := []byte("header")
+
+	for _, plaintext := range [][]byte{{}, []byte("hi"), make([]byte, 1000)} {
+		sealed, err := eg.Seal(rand.Reader, keyPair.Pub, plaintext, aad)
+		c.Assert(err, IsNil)
+		c.Assert(sealed, HasLen, 56+len(plaintext)+16)
+
+		opened, err := eg.Open(keyPair.Sec, sealed, aad)
+		c.Assert(err, IsNil)
+		c.Assert(string(opened), Equals, string(plaintext))
+	}
+
+	_, err = eg.Seal(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, []byte("hi"), nil)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_OpenRejectsModifiedMessages(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	otherKeyPair, err := eg.GenerateKeys(rand.Reader)
+	sealed, err := eg.Seal(rand.Reader, keyPair.Pub, []byte("secret message"), []byte("header"))
+
+	_, err = eg.Open(keyPair.Sec, sealed, []byte("other header"))
+	c.Assert(err, ErrorMatches, "cannot open the message")
+
+	_, err = eg.Open(otherKeyPair.Sec, sealed, []byte("header"))
+	c.Assert(err, ErrorMatches, "cannot open the message")
+
+	modified := append([]byte{}, sealed...)
+	modified[len(modified)-1] ^= 0x01
+	_, err = eg.Open(keyPair.Sec, modified, []byte("header"))
+	c.Assert(err, ErrorMatches, "cannot open the message")
+
+	_, err = eg.Open(keyPair.Sec, sealed[:55], []byte("header"))
+	c.Assert(err, ErrorMatches, "cannot open the message")
+
+	identity := append(testCurve.Identity().Encode(), sealed[56:]...)
+	_, err = eg.Open(keyPair.Sec, identity, []byte("header"))
+	c.Assert(err, ErrorMatches, "cannot open the message")
+}
@@ -0,0 +1,164 @@
+package elgamal
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// KeyShare is one party's Shamir share of an ElGamal secret key, the value of
+// the sharing polynomial at Index.
+type KeyShare struct {
+	Index uint32
+	X     curve.Scalar
+}
+
+// ThresholdPublicKey represents the public key of a secret key split between
+// several parties, along with the public verification key of each share.
+type ThresholdPublicKey struct {
+	*PublicKey
+	// Threshold is the number of shares needed to decrypt
+	Threshold int
+	// VerificationKeys holds G*x_i for the share with index i at i - 1
+	VerificationKeys []curve.Point
+}
+
+// PartialDecryption represents one party's share of the decryption of a
+// ciphertext, D = c1*x_i, with a proof that it was computed with the party's
+// key share.
+type PartialDecryption struct {
+	Index uint32
+	D     curve.Point
+	Proof *DLEQProof
+}
+
+var partialDecryptionLabel = []byte("elgamal_partial_decryption")
+
+// GenerateThresholdKeys generates a key pair and splits its secret key into n
+// shares, any t of which can decrypt. The key is generated by a trusted
+// dealer, who must erase it once the shares are handed out.
+func (eg *ElGamal) GenerateThresholdKeys(rand io.Reader, t, n int) (*ThresholdPublicKey, []*KeyShare, error) {
+	keyPair, err := eg.GenerateKeys(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return eg.SplitSecretKey(rand, keyPair.Pub, keyPair.Sec, t, n)
+}
+
+// SplitSecretKey splits the given secret key into n shares using Shamir's
+// secret sharing, so that any t of them can decrypt. Errors can result from
+// reading random or if t and n are out of range.
+func (eg *ElGamal) SplitSecretKey(rand io.Reader, pub *PublicKey, sec *SecretKey, t, n int) (*ThresholdPublicKey, []*KeyShare, error) {
+	if t < 1 || n < t || int64(n) >= 1<<31 {
+		return nil, nil, errors.New("invalid threshold")
+	}
+
+	// f(z) = X + a_1*z + ... + a_t-1*z^(t-1)
+	coefficients := []curve.Scalar{sec.X}
+	for i := 1; i < t; i++ {
+		a, err := eg.Curve.RandLongTermScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		coefficients = append(coefficients, a)
+	}
+
+	tpub := &ThresholdPublicKey{
+		PublicKey:        pub,
+		Threshold:        t,
+		VerificationKeys: make([]curve.Point, n),
+	}
+	shares := make([]*KeyShare, n)
+	for i := range shares {
+		index := uint32(i + 1)
+		x := eg.evaluatePolynomial(coefficients, eg.scalarFromInt(int64(index)))
+		shares[i] = &KeyShare{Index: index, X: x}
+		tpub.VerificationKeys[i] = eg.Curve.PrecompScalarMul(x)
+	}
+	return tpub, shares, nil
+}
+
+// evaluatePolynomial computes f(z) using Horner's method
+func (eg *ElGamal) evaluatePolynomial(coefficients []curve.Scalar, z curve.Scalar) curve.Scalar {
+	result := coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = eg.Curve.AddScalars(eg.Curve.Mul(result, z), coefficients[i])
+	}
+	return result
+}
+
+// PartialDecrypt computes the share of the decryption of the ciphertext held
+// by the given key share, with a Chaum-Pedersen proof that
+// log_G(G*x_i) = log_c1(D). Errors can result from reading random.
+func (eg *ElGamal) PartialDecrypt(rand io.Reader, share *KeyShare, ct *Ciphertext) (*PartialDecryption, error) {
+	d := eg.Curve.PointScalarMul(ct.C1, share.X)
+	proof, err := eg.proveDLEQ(rand, partialDecryptionLabel,
+		eg.Curve.G(), eg.Curve.PrecompScalarMul(share.X), ct.C1, d, share.X)
+	if err != nil {
+		return nil, err
+	}
+	return &PartialDecryption{Index: share.Index, D: d, Proof: proof}, nil
+}
+
+// VerifyPartialDecryption checks that the partial decryption was computed
+// from the ciphertext with the key share named by its index.
+func (eg *ElGamal) VerifyPartialDecryption(tpub *ThresholdPublicKey, ct *Ciphertext, pd *PartialDecryption) error {
+	if pd == nil || pd.Index < 1 || int(pd.Index) > len(tpub.VerificationKeys) ||
+		!eg.isValidPoint(pd.D) || !eg.isValidPoint(ct.C1) {
+		return errors.New("invalid partial decryption")
+	}
+	if !eg.verifyDLEQ(partialDecryptionLabel,
+		eg.Curve.G(), tpub.VerificationKeys[pd.Index-1], ct.C1, pd.D, pd.Proof) {
+		return errors.New("invalid partial decryption")
+	}
+	return nil
+}
+
+// CombinePartialDecryptions checks the given partial decryptions and
+// combines the first Threshold of them with Lagrange interpolation to recover
+// the plaintext of the ciphertext. An error results if any partial decryption
+// is invalid or repeated, or if there are too few of them.
+func (eg *ElGamal) CombinePartialDecryptions(tpub *ThresholdPublicKey, ct *Ciphertext, pds []*PartialDecryption) ([]byte, error) {
+	seen := make(map[uint32]bool, len(pds))
+	for _, pd := range pds {
+		if err := eg.VerifyPartialDecryption(tpub, ct, pd); err != nil {
+			return nil, err
+		}
+		if seen[pd.Index] {
+			return nil, errors.New("duplicate partial decryption")
+		}
+		seen[pd.Index] = true
+	}
+	if len(pds) < tpub.Threshold {
+		return nil, errors.New("not enough partial decryptions")
+	}
+	pds = pds[:tpub.Threshold]
+
+	indexes := make([]uint32, len(pds))
+	for i, pd := range pds {
+		indexes[i] = pd.Index
+	}
+
+	// m = c2 - sum(D_i*l_i)
+	s := eg.Curve.Identity()
+	for _, pd := range pds {
+		s = eg.Curve.AddPoints(s, eg.Curve.PointScalarMul(pd.D, eg.lagrangeCoefficient(pd.Index, indexes)))
+	}
+	return eg.Curve.SubPoints(ct.C2, s).Encode(), nil
+}
+
+// lagrangeCoefficient computes l_i = prod(j / (j - i)) for every index j
+// other than i, the coefficient of f(i) when interpolating f(0)
+func (eg *ElGamal) lagrangeCoefficient(i uint32, indexes []uint32) curve.Scalar {
+	num := eg.scalarFromInt(1)
+	den := eg.scalarFromInt(1)
+	for _, j := range indexes {
+		if j == i {
+			continue
+		}
+		num = eg.Curve.Mul(num, eg.scalarFromInt(int64(j)))
+		den = eg.Curve.Mul(den, eg.scalarFromInt(int64(j)-int64(i)))
+	}
+	return eg.Curve.Mul(num, eg.Curve.InvertScalar(den))
+}
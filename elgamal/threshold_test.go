@@ -0,0 +1,104 @@
+package elgamal
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func partialDecryptions(c *C, ct *Ciphertext, shares []*KeyShare) []*PartialDecryption {
+	pds := make([]*PartialDecryption, len(shares))
+	for i, share := range shares {
+		pd, err := eg.PartialDecrypt(rand.Reader, share, ct)
+		c.Assert(err, IsNil)
+		pds[i] = pd
+	}
+	return pds
+}
+
+func (s *EGSuite) Test_ThresholdDecryption(c *C) {
+	message := randomMessage()
+	tpub, shares, err := eg.GenerateThresholdKeys(rand.Reader, 3, 5)
+
+	c.Assert(err, IsNil)
+	c.Assert(shares, HasLen, 5)
+	c.Assert(tpub.VerificationKeys, HasLen, 5)
+
+	ct, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, message)
+	c.Assert(err, IsNil)
+
+	for _, subset := range [][]*KeyShare{
+		shares[:3],
+		shares[2:],
+		{shares[4], shares[0], shares[2]},
+		shares,
+	} {
+		m, err := eg.CombinePartialDecryptions(tpub, ct, partialDecryptions(c, ct, subset))
+		c.Assert(err, IsNil)
+		c.Assert(m, DeepEquals, message)
+	}
+}
+
+func (s *EGSuite) Test_SplitSecretKey(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	tpub, shares, err := eg.SplitSecretKey(rand.Reader, keyPair.Pub, keyPair.Sec, 2, 3)
+
+	c.Assert(err, IsNil)
+	c.Assert(tpub.Y, Equals, keyPair.Pub.Y)
+
+	for i, share := range shares {
+		c.Assert(share.Index, Equals, uint32(i+1))
+		c.Assert(testCurve.EqualPoints(tpub.VerificationKeys[i], testCurve.PrecompScalarMul(share.X)), Equals, true)
+	}
+
+	one, _, err := eg.SplitSecretKey(rand.Reader, keyPair.Pub, keyPair.Sec, 1, 1)
+	c.Assert(err, IsNil)
+	c.Assert(one.VerificationKeys[0], DeepEquals, keyPair.Pub.Y)
+
+	_, _, err = eg.SplitSecretKey(rand.Reader, keyPair.Pub, keyPair.Sec, 0, 3)
+	c.Assert(err, ErrorMatches, "invalid threshold")
+
+	_, _, err = eg.SplitSecretKey(rand.Reader, keyPair.Pub, keyPair.Sec, 4, 3)
+	c.Assert(err, ErrorMatches, "invalid threshold")
+
+	_, _, err = eg.SplitSecretKey(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, keyPair.Sec, 2, 3)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *EGSuite) Test_CombinePartialDecryptionsErrors(c *C) {
+	message := randomMessage()
+	tpub, shares, err := eg.GenerateThresholdKeys(rand.Reader, 3, 5)
+	c.Assert(err, IsNil)
+	ct, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, message)
+	c.Assert(err, IsNil)
+	pds := partialDecryptions(c, ct, shares)
+
+	_, err = eg.CombinePartialDecryptions(tpub, ct, pds[:2])
+	c.Assert(err, ErrorMatches, "not enough partial decryptions")
+
+	_, err = eg.CombinePartialDecryptions(tpub, ct, []*PartialDecryption{pds[0], pds[1], pds[1]})
+	c.Assert(err, ErrorMatches, "duplicate partial decryption")
+
+	forged := *pds[2]
+	forged.D = testCurve.AddPoints(forged.D, testCurve.G())
+	_, err = eg.CombinePartialDecryptions(tpub, ct, []*PartialDecryption{pds[0], pds[1], &forged})
+	c.Assert(err, ErrorMatches, "invalid partial decryption")
+
+	misplaced := *pds[2]
+	misplaced.Index = pds[3].Index
+	c.Assert(eg.VerifyPartialDecryption(tpub, ct, &misplaced), ErrorMatches, "invalid partial decryption")
+
+	misplaced.Index = 6
+	c.Assert(eg.VerifyPartialDecryption(tpub, ct, &misplaced), ErrorMatches, "invalid partial decryption")
+
+	other, err := eg.EncryptCiphertext(rand.Reader, tpub.PublicKey, message)
+	c.Assert(err, IsNil)
+	c.Assert(eg.VerifyPartialDecryption(tpub, other, pds[0]), ErrorMatches, "invalid partial decryption")
+	c.Assert(eg.VerifyPartialDecryption(tpub, ct, pds[0]), IsNil)
+
+	_, err = eg.PartialDecrypt(testHelpers.FixedRandReader([]byte{0x00}), shares[0], ct)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
@@ -0,0 +1,214 @@
+package ratchet
+
+import (
+	"errors"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/ecdh"
+)
+
+// header is the header of a message: the sender's ratchet public key, the
+// number of messages in its previous sending chain and the number of the
+// message in the current one
+type header struct {
+	dh    curve.Point
+	pn, n uint32
+}
+
+func appendUint32(b []byte, n uint32) []byte {
+	return append(b, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// encode serializes the header as DH || PN || N, with 4-byte big-endian
+// numbers
+func (h *header) encode() []byte {
+	return appendUint32(appendUint32(h.dh.Encode(), h.pn), h.n)
+}
+
+func (r *Ratchet) decodeHeader(b []byte) (*header, error) {
+	d := r.newDecoder(b)
+	h := &header{dh: d.point(), pn: d.number(), n: d.number()}
+	if !d.finish() {
+		return nil, ErrInvalidMessage
+	}
+	return h, nil
+}
+
+// decoder reads the fields of an encoding in order, remembering whether any
+// of them was invalid
+type decoder struct {
+	ratchet *Ratchet
+	b       []byte
+	valid   bool
+}
+
+func (r *Ratchet) newDecoder(b []byte) *decoder {
+	return &decoder{ratchet: r, b: b, valid: true}
+}
+
+func (d *decoder) next(n int) []byte {
+	if !d.valid || len(d.b) < n {
+		d.valid = false
+		return nil
+	}
+	field := d.b[:n]
+	d.b = d.b[n:]
+	return field
+}
+
+func (d *decoder) flag() byte {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) number() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+// data reads a 4-byte big-endian length and that many bytes. An empty field
+// is read as nil.
+func (d *decoder) data() []byte {
+	n := d.number()
+	if uint64(n) > uint64(len(d.b)) {
+		d.valid = false
+		return nil
+	}
+	if n == 0 {
+		return nil
+	}
+	return append([]byte{}, d.next(int(n))...)
+}
+
+// key reads a key that is either missing or keySize bytes long
+func (d *decoder) key() []byte {
+	k := d.data()
+	if k != nil && len(k) != keySize {
+		d.valid = false
+	}
+	return k
+}
+
+func (d *decoder) point() curve.Point {
+	b := d.next(len(d.ratchet.Curve.G().Encode()))
+	if b == nil {
+		return nil
+	}
+	p, err := d.ratchet.Curve.DecodePointStrict(b)
+	if err != nil {
+		d.valid = false
+		return nil
+	}
+	return p
+}
+
+func (d *decoder) scalar() curve.Scalar {
+	b := d.next(len(d.ratchet.Curve.Q().Encode()))
+	if b == nil {
+		return nil
+	}
+	s, err := d.ratchet.Curve.DecodeScalar(b)
+	if err != nil {
+		d.valid = false
+		return nil
+	}
+	return s
+}
+
+// finish reports whether every field was valid and nothing is left over
+func (d *decoder) finish() bool {
+	return d.valid && len(d.b) == 0
+}
+
+func appendData(b, data []byte) []byte {
+	return append(appendUint32(b, uint32(len(data))), data...)
+}
+
+// Encode serializes the session so it can be stored and restored with
+// DecodeSession. The encoding holds every secret key of the session, so it
+// must be protected like them. The fields are, in order: whether headers
+// are encrypted, the ratchet secret key, whether the other party's ratchet
+// public key is known and the key, then RK, CKs, CKr, Ns, Nr, PN, HKs, HKr,
+// NHKs and NHKr, and last the number of stored message keys followed by each
+// key's chain, message number and key. Keys are prefixed with their 4-byte
+// big-endian length, which is zero for missing keys.
+func (s *Session) Encode() []byte {
+	b := []byte{0x00}
+	if s.ratchet.HeaderEncryption {
+		b[0] = 0x01
+	}
+	b = append(b, s.dhs.Sec.X.Encode()...)
+	if s.dhr != nil {
+		b = append(append(b, 0x01), s.dhr.Y.Encode()...)
+	} else {
+		b = append(b, 0x00)
+	}
+
+	b = appendData(b, s.rk)
+	b = appendData(b, s.cks)
+	b = appendData(b, s.ckr)
+	b = appendUint32(b, s.ns)
+	b = appendUint32(b, s.nr)
+	b = appendUint32(b, s.pn)
+	for _, k := range [][]byte{s.hks, s.hkr, s.nhks, s.nhkr} {
+		b = appendData(b, k)
+	}
+
+	b = appendUint32(b, uint32(len(s.skipped)))
+	for _, k := range s.skipped {
+		b = appendData(b, k.chain)
+		b = appendUint32(b, k.n)
+		b = appendData(b, k.mk)
+	}
+	return b
+}
+
+// DecodeSession restores a session serialized by Session.Encode. An error
+// results if the encoding is invalid, if it was made by a session that
+// encrypts headers differently or if it keeps more than MaxSkip message
+// keys.
+func (r *Ratchet) DecodeSession(b []byte) (*Session, error) {
+	d := r.newDecoder(b)
+	s := &Session{ratchet: r}
+
+	encrypted := d.flag()
+	x := d.scalar()
+	if d.valid {
+		s.dhs = &ecdh.KeyPair{Pub: &ecdh.PublicKey{Y: r.Curve.PrecompScalarMul(x)}, Sec: &ecdh.SecretKey{X: x}}
+	}
+	switch d.flag() {
+	case 0x00:
+	case 0x01:
+		s.dhr = &ecdh.PublicKey{Y: d.point()}
+	default:
+		d.valid = false
+	}
+
+	s.rk = d.data()
+	s.cks, s.ckr = d.key(), d.key()
+	s.ns, s.nr, s.pn = d.number(), d.number(), d.number()
+	s.hks, s.hkr, s.nhks, s.nhkr = d.key(), d.key(), d.key(), d.key()
+
+	n := d.number()
+	if n > r.maxSkip() {
+		d.valid = false
+	}
+	for ; d.valid && n > 0; n-- {
+		k := skippedKey{chain: d.data(), n: d.number(), mk: d.key()}
+		if k.chain == nil || k.mk == nil {
+			d.valid = false
+		}
+		s.skipped = append(s.skipped, k)
+	}
+
+	if !d.finish() || encrypted > 0x01 || (encrypted == 0x01) != r.HeaderEncryption || len(s.rk) < keySize {
+		return nil, errors.New("invalid session encoding")
+	}
+	return s, nil
+}
@@ -0,0 +1,90 @@
+package ratchet
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *RatchetSuite) Test_SessionsSurviveEncoding(c *C) {
+	for _, r := range ratchets {
+		alice, bob := newSessions(c, r)
+
+		restored, err := r.DecodeSession(bob.Encode())
+		c.Assert(err, IsNil)
+		bob = restored
+
+		skippedA := send(c, alice, "a0")
+		receive(c, bob, send(c, alice, "a1"), "a1")
+		receive(c, alice, send(c, bob, "b0"), "b0")
+		skippedB := send(c, bob, "b1")
+		receive(c, alice, send(c, bob, "b2"), "b2")
+
+		for _, session := range []**Session{&alice, &bob} {
+			encoded := (*session).Encode()
+			restored, err := r.DecodeSession(encoded)
+			c.Assert(err, IsNil)
+			c.Assert(restored.Encode(), DeepEquals, encoded)
+			*session = restored
+		}
+
+		receive(c, bob, skippedA, "a0")
+		receive(c, alice, skippedB, "b1")
+		receive(c, bob, send(c, alice, "a2"), "a2")
+		receive(c, alice, send(c, bob, "b3"), "b3")
+	}
+}
+
+func (s *RatchetSuite) Test_DecodeSessionRejectsInvalidEncodings(c *C) {
+	for i, r := range ratchets {
+		alice, bob := newSessions(c, r)
+		receive(c, bob, send(c, alice, "a1"), "a1")
+		encoded := bob.Encode()
+
+		for _, invalid := range [][]byte{
+			nil,
+			encoded[:len(encoded)-1],
+			append(append([]byte{}, encoded...), 0x00),
+			append([]byte{0x02}, encoded[1:]...),
+		} {
+			_, err := r.DecodeSession(invalid)
+			c.Assert(err, ErrorMatches, "invalid session encoding")
+		}
+
+		// a session cannot change whether it encrypts headers
+		_, err := ratchets[1-i].DecodeSession(encoded)
+		c.Assert(err, ErrorMatches, "invalid session encoding")
+	}
+
+	// a receiver that has not received a message yet has no peer key
+	_, bob := newSessions(c, ratchets[0])
+	encoded := bob.Encode()
+	_, err := ratchets[0].DecodeSession(encoded)
+	c.Assert(err, IsNil)
+	encoded[1+56] = 0x02
+	_, err = ratchets[0].DecodeSession(encoded)
+	c.Assert(err, ErrorMatches, "invalid session encoding")
+
+	_, err = rand.Read(encoded)
+	c.Assert(err, IsNil)
+	_, err = ratchets[0].DecodeSession(encoded)
+	c.Assert(err, ErrorMatches, "invalid session encoding")
+}
+
+func (s *RatchetSuite) Test_DecodeSessionRejectsMoreSkippedKeysThanMaxSkip(c *C) {
+	for _, base := range ratchets {
+		r := &Ratchet{Curve: base.Curve, HeaderEncryption: base.HeaderEncryption, MaxSkip: 3}
+		alice, bob := newSessions(c, r)
+		for i := 0; i < 3; i++ {
+			send(c, alice, "a")
+		}
+		receive(c, bob, send(c, alice, "a3"), "a3")
+		encoded := bob.Encode()
+
+		_, err := r.DecodeSession(encoded)
+		c.Assert(err, IsNil)
+		smaller := &Ratchet{Curve: base.Curve, HeaderEncryption: base.HeaderEncryption, MaxSkip: 2}
+		_, err = smaller.DecodeSession(encoded)
+		c.Assert(err, ErrorMatches, "invalid session encoding")
+	}
+}
@@ -0,0 +1,101 @@
+package ratchet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+const (
+	keySize   = 32
+	nonceSize = 12
+)
+
+// kdfRoot computes RK || CK || NHK = SHAKE-256("ratchet_root" || RK || DH, 96),
+// the next root key, the chain key of the new chain and the header key of
+// the chain after it
+func kdfRoot(rk, dh []byte) (rootKey, chainKey, headerKey []byte) {
+	out := make([]byte, 3*keySize)
+	sha3.ShakeSum256(out, curve.Append([]byte("ratchet_root"), rk, dh))
+	return out[:keySize], out[keySize : 2*keySize], out[2*keySize:]
+}
+
+// kdfChain computes the next chain key, SHAKE-256("ratchet_chain" || CK, 32),
+// and the message key, SHAKE-256("ratchet_message" || CK, 32)
+func kdfChain(ck []byte) (chainKey, messageKey []byte) {
+	chainKey = make([]byte, keySize)
+	sha3.ShakeSum256(chainKey, curve.Append([]byte("ratchet_chain"), ck))
+	messageKey = make([]byte, keySize)
+	sha3.ShakeSum256(messageKey, curve.Append([]byte("ratchet_message"), ck))
+	return chainKey, messageKey
+}
+
+// initialHeaderKeys computes HKa || NHKb = SHAKE-256("ratchet_header" || SK, 64),
+// the header key the sender starts with and the one the receiver answers
+// with
+func initialHeaderKeys(sk []byte) (senderKey, receiverKey []byte) {
+	out := make([]byte, 2*keySize)
+	sha3.ShakeSum256(out, curve.Append([]byte("ratchet_header"), sk))
+	return out[:keySize], out[keySize:]
+}
+
+func newAEAD(key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic("programmer error: invalid AES key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic("programmer error: cannot create GCM")
+	}
+	return aead
+}
+
+// messageCipher derives the AES-256-GCM key and nonce of a message as
+// SHAKE-256("ratchet_message_key" || MK, 44). Every message key is used once,
+// so the nonce can be derived along with it.
+func messageCipher(mk []byte) (cipher.AEAD, []byte) {
+	out := make([]byte, keySize+nonceSize)
+	sha3.ShakeSum256(out, curve.Append([]byte("ratchet_message_key"), mk))
+	return newAEAD(out[:keySize]), out[keySize:]
+}
+
+func sealMessage(mk, plaintext, ad []byte) []byte {
+	aead, nonce := messageCipher(mk)
+	return aead.Seal(nil, nonce, plaintext, ad)
+}
+
+func openMessage(mk, ciphertext, ad []byte) ([]byte, error) {
+	aead, nonce := messageCipher(mk)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+	return plaintext, nil
+}
+
+// encryptHeader encrypts a header as nonce || AES-256-GCM(HK, header). A
+// header key is used for a whole chain, so the nonce is random. Errors can
+// result from reading random.
+func encryptHeader(rand io.Reader, hk, h []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, errors.New("cannot source enough entropy")
+	}
+	return newAEAD(hk).Seal(nonce, nonce, h, nil), nil
+}
+
+// decryptHeader reports whether the header was encrypted with hk, and
+// returns it if so
+func decryptHeader(hk, b []byte) ([]byte, bool) {
+	if hk == nil || len(b) < nonceSize {
+		return nil, false
+	}
+	h, err := newAEAD(hk).Open(nil, b[:nonceSize], b[nonceSize:], nil)
+	return h, err == nil
+}
@@ -0,0 +1,321 @@
+package ratchet
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/ecdh"
+)
+
+// DefaultMaxSkip is the number of message keys a session keeps for messages
+// that have not arrived when Ratchet.MaxSkip is zero
+const DefaultMaxSkip = 1000
+
+// Ratchet is an instance of the Double Ratchet algorithm, which encrypts
+// each message of a conversation with its own key after a key exchange.
+//
+// Message keys come from chain keys that are hashed forward with SHAKE-256
+// for every message, so keys of earlier messages cannot be recomputed. Each
+// time the other party replies, both parties also mix a new ECDH secret into
+// the root key the chains are derived from, so a compromised session heals
+// once the attacker misses a reply. Messages can arrive out of order: the
+// keys of messages that were skipped are kept until they arrive, up to
+// MaxSkip of them.
+type Ratchet struct {
+	Curve Curve
+	// MaxSkip bounds the number of message keys kept for messages that have
+	// not arrived, and so how many messages can be skipped at once.
+	// DefaultMaxSkip is used if it is zero.
+	MaxSkip uint32
+	// HeaderEncryption encrypts message headers with header keys that are
+	// ratcheted along with the chains, so the ratchet public keys and
+	// message numbers cannot be seen by others.
+	HeaderEncryption bool
+}
+
+// Curve defines what curve functions are required for the Double Ratchet
+type Curve interface {
+	ecdh.Curve
+	curve.StrictPointDecoder
+	curve.ScalarDecoder
+}
+
+// Message is an encrypted message: its header, which is encrypted if the
+// session encrypts headers, and its ciphertext.
+type Message struct {
+	Header     []byte
+	Ciphertext []byte
+}
+
+// skippedKey is the message key of a message that has not arrived, found by
+// the number of the message and the ratchet public key of its chain, or the
+// header key of its chain if headers are encrypted
+type skippedKey struct {
+	chain []byte
+	n     uint32
+	mk    []byte
+}
+
+// Session holds one party's state of a conversation.
+type Session struct {
+	ratchet *Ratchet
+	dhs     *ecdh.KeyPair
+	dhr     *ecdh.PublicKey
+	rk      []byte
+	cks     []byte
+	ckr     []byte
+	ns      uint32
+	nr      uint32
+	pn      uint32
+	hks     []byte
+	hkr     []byte
+	nhks    []byte
+	nhkr    []byte
+	skipped []skippedKey
+}
+
+var (
+	// ErrInvalidMessage is returned when a message cannot be decrypted
+	ErrInvalidMessage = errors.New("invalid ratchet message")
+	// ErrTooManySkipped is returned when a message would skip more than
+	// MaxSkip messages
+	ErrTooManySkipped = errors.New("too many skipped messages")
+	// ErrCannotSend is returned when the party that receives first tries to
+	// send before any message has arrived
+	ErrCannotSend = errors.New("cannot send before receiving a message")
+)
+
+func (r *Ratchet) ecdh() *ecdh.ECDH {
+	return &ecdh.ECDH{Curve: r.Curve}
+}
+
+func (r *Ratchet) maxSkip() uint32 {
+	if r.MaxSkip == 0 {
+		return DefaultMaxSkip
+	}
+	return r.MaxSkip
+}
+
+// NewSender starts a session as the party that sends first, from the secret
+// shared by a key exchange and the ratchet public key of the other party.
+// Errors can result from reading random, or if the secret is shorter than
+// 32 bytes or the public key is invalid.
+func (r *Ratchet) NewSender(rand io.Reader, sk []byte, peer *ecdh.PublicKey) (*Session, error) {
+	if len(sk) < keySize {
+		return nil, errors.New("invalid shared secret")
+	}
+	dhs, err := r.ecdh().GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	dh, err := r.ecdh().SharedSecret(dhs.Sec, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{ratchet: r, dhs: dhs, dhr: peer}
+	s.rk, s.cks, s.nhks = kdfRoot(sk, dh)
+	if r.HeaderEncryption {
+		s.hks, s.nhkr = initialHeaderKeys(sk)
+	}
+	return s, nil
+}
+
+// NewReceiver starts a session as the party that receives first, from the
+// secret shared by a key exchange and the ratchet key pair whose public key
+// the sender uses. It cannot send until the first message has arrived. An
+// error results if the secret is shorter than 32 bytes.
+func (r *Ratchet) NewReceiver(sk []byte, keys *ecdh.KeyPair) (*Session, error) {
+	if len(sk) < keySize {
+		return nil, errors.New("invalid shared secret")
+	}
+	s := &Session{ratchet: r, dhs: keys, rk: append([]byte{}, sk...)}
+	if r.HeaderEncryption {
+		s.nhkr, s.nhks = initialHeaderKeys(sk)
+	}
+	return s, nil
+}
+
+// Encrypt encrypts and authenticates the plaintext as the next message of
+// the session, and authenticates the associated data, which is not sent.
+// Errors can result from reading random or if the session cannot send yet.
+func (s *Session) Encrypt(rand io.Reader, plaintext, ad []byte) (*Message, error) {
+	if s.cks == nil {
+		return nil, ErrCannotSend
+	}
+	ck, mk := kdfChain(s.cks)
+
+	h := (&header{dh: s.dhs.Pub.Y, pn: s.pn, n: s.ns}).encode()
+	if s.ratchet.HeaderEncryption {
+		var err error
+		if h, err = encryptHeader(rand, s.hks, h); err != nil {
+			return nil, err
+		}
+	}
+
+	s.cks = ck
+	s.ns++
+	return &Message{Header: h, Ciphertext: sealMessage(mk, plaintext, curve.Append(ad, h))}, nil
+}
+
+// Decrypt decrypts and authenticates a message of the other party with the
+// same associated data it was encrypted with. Messages can be decrypted in
+// any order, but only once each. The session only changes if the message is
+// authentic. Errors can result from reading random, if the message is
+// invalid, or if it would skip more than MaxSkip messages.
+func (s *Session) Decrypt(rand io.Reader, m *Message, ad []byte) ([]byte, error) {
+	if m == nil {
+		return nil, ErrInvalidMessage
+	}
+	next := s.clone()
+	plaintext, err := next.decrypt(rand, m, curve.Append(ad, m.Header))
+	if err != nil {
+		return nil, err
+	}
+	*s = *next
+	return plaintext, nil
+}
+
+func (s *Session) clone() *Session {
+	c := *s
+	c.skipped = append([]skippedKey{}, s.skipped...)
+	return &c
+}
+
+func (s *Session) decrypt(rand io.Reader, m *Message, ad []byte) ([]byte, error) {
+	if i := s.findSkipped(m.Header); i >= 0 {
+		mk := s.skipped[i].mk
+		s.skipped = append(s.skipped[:i:i], s.skipped[i+1:]...)
+		return openMessage(mk, m.Ciphertext, ad)
+	}
+
+	h, newChain, err := s.readHeader(m.Header)
+	if err != nil {
+		return nil, err
+	}
+	if newChain {
+		if err := s.skip(h.pn); err != nil {
+			return nil, err
+		}
+		if err := s.dhRatchet(rand, h); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.skip(h.n); err != nil {
+		return nil, err
+	}
+	if s.ckr == nil {
+		return nil, ErrInvalidMessage
+	}
+
+	ck, mk := kdfChain(s.ckr)
+	s.ckr = ck
+	s.nr++
+	return openMessage(mk, m.Ciphertext, ad)
+}
+
+// readHeader decodes the header, decrypting it with the current or the next
+// receiving header key if headers are encrypted, and reports whether the
+// message starts a new receiving chain
+func (s *Session) readHeader(b []byte) (*header, bool, error) {
+	if !s.ratchet.HeaderEncryption {
+		h, err := s.ratchet.decodeHeader(b)
+		if err != nil {
+			return nil, false, err
+		}
+		return h, s.dhr == nil || !s.ratchet.Curve.EqualPoints(h.dh, s.dhr.Y), nil
+	}
+
+	if plain, ok := decryptHeader(s.hkr, b); ok {
+		h, err := s.ratchet.decodeHeader(plain)
+		return h, false, err
+	}
+	if plain, ok := decryptHeader(s.nhkr, b); ok {
+		h, err := s.ratchet.decodeHeader(plain)
+		return h, true, err
+	}
+	return nil, false, ErrInvalidMessage
+}
+
+// chainID returns what the keys of messages skipped in the current
+// receiving chain are stored by
+func (s *Session) chainID() []byte {
+	if s.ratchet.HeaderEncryption {
+		return s.hkr
+	}
+	return s.dhr.Y.Encode()
+}
+
+// findSkipped returns the index of the stored key of the message with the
+// given header, or -1 if there is none
+func (s *Session) findSkipped(b []byte) int {
+	encrypted := s.ratchet.HeaderEncryption
+	var plain *header
+	if !encrypted {
+		plain, _ = s.ratchet.decodeHeader(b)
+	}
+	for i, k := range s.skipped {
+		h := plain
+		if encrypted {
+			dec, ok := decryptHeader(k.chain, b)
+			if !ok {
+				continue
+			}
+			h, _ = s.ratchet.decodeHeader(dec)
+		}
+		if h != nil && h.n == k.n && (encrypted || string(h.dh.Encode()) == string(k.chain)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// skip stores the keys of the messages of the receiving chain before the
+// given message number, dropping the oldest stored keys beyond MaxSkip
+func (s *Session) skip(until uint32) error {
+	if s.ckr == nil {
+		return nil
+	}
+	max := s.ratchet.maxSkip()
+	if uint64(s.nr)+uint64(max) < uint64(until) {
+		return ErrTooManySkipped
+	}
+	for ; s.nr < until; s.nr++ {
+		var mk []byte
+		s.ckr, mk = kdfChain(s.ckr)
+		s.skipped = append(s.skipped, skippedKey{chain: s.chainID(), n: s.nr, mk: mk})
+	}
+	if n := uint64(len(s.skipped)); n > uint64(max) {
+		s.skipped = s.skipped[n-uint64(max):]
+	}
+	return nil
+}
+
+// dhRatchet starts a new receiving chain from the ratchet public key of the
+// message and a new sending chain from a new ratchet key pair
+func (s *Session) dhRatchet(rand io.Reader, h *header) error {
+	e := s.ratchet.ecdh()
+	dhr := &ecdh.PublicKey{Y: h.dh}
+	dh, err := e.SharedSecret(s.dhs.Sec, dhr)
+	if err != nil {
+		return ErrInvalidMessage
+	}
+	dhs, err := e.GenerateKey(rand)
+	if err != nil {
+		return err
+	}
+
+	s.pn, s.ns, s.nr = s.ns, 0, 0
+	s.hks, s.hkr = s.nhks, s.nhkr
+	s.dhr = dhr
+	s.rk, s.ckr, s.nhkr = kdfRoot(s.rk, dh)
+
+	s.dhs = dhs
+	dh, err = e.SharedSecret(s.dhs.Sec, s.dhr)
+	if err != nil {
+		return ErrInvalidMessage
+	}
+	s.rk, s.cks, s.nhks = kdfRoot(s.rk, dh)
+	return nil
+}
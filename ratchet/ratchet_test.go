@@ -0,0 +1,225 @@
+package ratchet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/ecdh"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type RatchetSuite struct{}
+
+var _ = Suite(&RatchetSuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	ratchets  = []*Ratchet{
+		{Curve: testCurve},
+		{Curve: testCurve, HeaderEncryption: true},
+	}
+	ad = []byte("associated data")
+)
+
+// newSessions starts a conversation between alice, who sends first, and bob
+func newSessions(c *C, r *Ratchet) (alice, bob *Session) {
+	sk := make([]byte, 64)
+	_, err := rand.Read(sk)
+	c.Assert(err, IsNil)
+	bobKeys, err := r.ecdh().GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+
+	alice, err = r.NewSender(rand.Reader, sk, bobKeys.Pub)
+	c.Assert(err, IsNil)
+	bob, err = r.NewReceiver(sk, bobKeys)
+	c.Assert(err, IsNil)
+	return alice, bob
+}
+
+func send(c *C, s *Session, text string) *Message {
+	m, err := s.Encrypt(rand.Reader, []byte(text), ad)
+	c.Assert(err, IsNil)
+	return m
+}
+
+func receive(c *C, s *Session, m *Message, text string) {
+	plaintext, err := s.Decrypt(rand.Reader, m, ad)
+	c.Assert(err, IsNil)
+	c.Assert(string(plaintext), Equals, text)
+}
+
+func (s *RatchetSuite) Test_Conversation(c *C) {
+	for _, r := range ratchets {
+		alice, bob := newSessions(c, r)
+
+		_, err := bob.Encrypt(rand.Reader, []byte("hi"), ad)
+		c.Assert(err, Equals, ErrCannotSend)
+
+		receive(c, bob, send(c, alice, "hello bob"), "hello bob")
+		receive(c, bob, send(c, alice, "are you there?"), "are you there?")
+		receive(c, alice, send(c, bob, "hello alice"), "hello alice")
+		receive(c, bob, send(c, alice, "good"), "good")
+		receive(c, alice, send(c, bob, "bye"), "bye")
+		receive(c, alice, send(c, bob, "really"), "really")
+		receive(c, bob, send(c, alice, ""), "")
+	}
+}
+
+func (s *RatchetSuite) Test_EveryMessageHasItsOwnKey(c *C) {
+	for _, r := range ratchets {
+		alice, bob := newSessions(c, r)
+
+		m1 := send(c, alice, "same")
+		m2 := send(c, alice, "same")
+		c.Assert(m1.Ciphertext, Not(DeepEquals), m2.Ciphertext)
+		receive(c, bob, m1, "same")
+		receive(c, bob, m2, "same")
+	}
+}
+
+func (s *RatchetSuite) Test_OutOfOrderDelivery(c *C) {
+	for _, r := range ratchets {
+		alice, bob := newSessions(c, r)
+
+		first := []*Message{send(c, alice, "a0"), send(c, alice, "a1"), send(c, alice, "a2"), send(c, alice, "a3")}
+		receive(c, bob, first[2], "a2")
+		receive(c, alice, send(c, bob, "b0"), "b0")
+
+		// a message of a new chain arrives before the end of the old one
+		second := []*Message{send(c, alice, "a4"), send(c, alice, "a5")}
+		receive(c, bob, second[1], "a5")
+		receive(c, bob, first[3], "a3")
+		receive(c, bob, first[0], "a0")
+		receive(c, bob, second[0], "a4")
+		receive(c, bob, first[1], "a1")
+
+		for _, m := range append(first, second...) {
+			_, err := bob.Decrypt(rand.Reader, m, ad)
+			c.Assert(err, Equals, ErrInvalidMessage)
+		}
+		c.Assert(bob.skipped, HasLen, 0)
+	}
+}
+
+func (s *RatchetSuite) Test_SkippedKeysAreBounded(c *C) {
+	for _, base := range ratchets {
+		r := &Ratchet{Curve: base.Curve, HeaderEncryption: base.HeaderEncryption, MaxSkip: 3}
+		alice, bob := newSessions(c, r)
+
+		var first []*Message
+		for i := 0; i < 5; i++ {
+			first = append(first, send(c, alice, "a"))
+		}
+		_, err := bob.Decrypt(rand.Reader, first[4], ad)
+		c.Assert(err, Equals, ErrTooManySkipped)
+		receive(c, bob, first[3], "a")
+		c.Assert(bob.skipped, HasLen, 3)
+
+		receive(c, alice, send(c, bob, "b"), "b")
+		var second []*Message
+		for i := 0; i < 3; i++ {
+			second = append(second, send(c, alice, "c"))
+		}
+		receive(c, bob, second[2], "c")
+
+		// the keys of the first chain were dropped to keep at most 3
+		c.Assert(bob.skipped, HasLen, 3)
+		_, err = bob.Decrypt(rand.Reader, first[0], ad)
+		c.Assert(err, Equals, ErrInvalidMessage)
+		receive(c, bob, first[4], "a")
+		receive(c, bob, second[0], "c")
+		receive(c, bob, second[1], "c")
+	}
+}
+
+func (s *RatchetSuite) Test_TamperedMessagesAreRejected(c *C) {
+	for _, r := range ratchets {
+		alice, bob := newSessions(c, r)
+		m := send(c, alice, "hello")
+		before := bob.Encode()
+
+		flip := func(b []byte, i int) []byte {
+			b = append([]byte{}, b...)
+			b[i] ^= 0x01
+			return b
+		}
+		for _, tampered := range []*Message{
+			{Header: m.Header, Ciphertext: flip(m.Ciphertext, 0)},
+			{Header: flip(m.Header, 0), Ciphertext: m.Ciphertext},
+			{Header: flip(m.Header, len(m.Header)-1), Ciphertext: m.Ciphertext},
+			{Header: m.Header[1:], Ciphertext: m.Ciphertext},
+			{Header: m.Header, Ciphertext: m.Ciphertext[1:]},
+			{},
+			nil,
+		} {
+			_, err := bob.Decrypt(rand.Reader, tampered, ad)
+			c.Assert(err, NotNil)
+		}
+		_, err := bob.Decrypt(rand.Reader, m, []byte("other data"))
+		c.Assert(err, Equals, ErrInvalidMessage)
+
+		// failed messages leave the session as it was
+		c.Assert(bob.Encode(), DeepEquals, before)
+		receive(c, bob, m, "hello")
+	}
+}
+
+func (s *RatchetSuite) Test_HeadersAreOnlyReadableWithHeaderEncryption(c *C) {
+	for _, r := range ratchets {
+		alice, bob := newSessions(c, r)
+
+		m := send(c, alice, "hello")
+
+		pub := alice.dhs.Pub.Y.Encode()
+		c.Assert(bytes.Contains(m.Header, pub), Equals, !r.HeaderEncryption)
+		receive(c, bob, m, "hello")
+	}
+}
+
+func (s *RatchetSuite) Test_MessagesOfAnotherConversationAreRejected(c *C) {
+	for _, r := range ratchets {
+		alice, _ := newSessions(c, r)
+		_, bob := newSessions(c, r)
+
+		_, err := bob.Decrypt(rand.Reader, send(c, alice, "hello"), ad)
+		c.Assert(err, Equals, ErrInvalidMessage)
+	}
+}
+
+func (s *RatchetSuite) Test_NewSessionErrors(c *C) {
+	r := ratchets[0]
+	keys, err := r.ecdh().GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	sk := make([]byte, keySize)
+
+	_, err = r.NewSender(rand.Reader, sk[1:], keys.Pub)
+	c.Assert(err, ErrorMatches, "invalid shared secret")
+	_, err = r.NewReceiver(sk[1:], keys)
+	c.Assert(err, ErrorMatches, "invalid shared secret")
+
+	_, err = r.NewSender(rand.Reader, sk, &ecdh.PublicKey{Y: testCurve.Identity()})
+	c.Assert(err, Equals, ecdh.ErrInvalidPublicKey)
+}
+
+func (s *RatchetSuite) Test_RandomFailures(c *C) {
+	fixed := testHelpers.FixedRandReader([]byte{0x00})
+	r := ratchets[1]
+	alice, bob := newSessions(c, r)
+
+	_, err := r.NewSender(fixed, make([]byte, keySize), bob.dhs.Pub)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, err = alice.Encrypt(fixed, []byte("hello"), ad)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	m := send(c, alice, "hello")
+	_, err = bob.Decrypt(fixed, m, ad)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+	receive(c, bob, m, "hello")
+}
@@ -0,0 +1,194 @@
+package ringsig
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// RingSignature represents an AOS ring signature over a ring of n public
+// keys, the challenge c0 at the first member and a response for every
+// member.
+type RingSignature struct {
+	C0 curve.Scalar
+	S  []curve.Scalar
+}
+
+var aosLabel = []byte("ringsig_aos")
+
+// nextChallenge computes the challenge of the member following member i of a
+// ring, from the response and the challenge of member i
+type nextChallenge func(i int, s, c curve.Scalar) curve.Scalar
+
+// SignRing signs the message with the given secret key on behalf of a ring
+// of any number of public keys, one of which must match sec, following Abe,
+// Ohkubo and Suzuki. The signature has one scalar per member and does not
+// reveal which member signed. Errors can result from reading random or if
+// sec does not match any of the public keys.
+func (r *RingSig) SignRing(rand io.Reader, sec *SecretKey, ring []*PublicKey, message []byte) (*RingSignature, error) {
+	signer, err := r.signerIndex(sec, ring)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.ringPrefix(aosLabel, ring, message)
+
+	first := func(alpha curve.Scalar) curve.Scalar {
+		return r.Curve.HashToScalar(prefix, r.Curve.PrecompScalarMul(alpha))
+	}
+
+	c0, s, err := r.signRing(rand, sec.A, signer, len(ring), first, r.aosChallenge(prefix, ring))
+	if err != nil {
+		return nil, err
+	}
+	return &RingSignature{C0: c0, S: s}, nil
+}
+
+// VerifyRing checks that the signature was made on the message by a member
+// of the ring, given in the order used to sign.
+func (r *RingSig) VerifyRing(ring []*PublicKey, message []byte, sig *RingSignature) error {
+	if !r.isValidRing(ring) || sig == nil || !r.isWellFormedRing(sig.C0, sig.S, len(ring)) {
+		return ErrInvalidSignature
+	}
+	prefix := r.ringPrefix(aosLabel, ring, message)
+	if !r.verifyRing(sig.C0, sig.S, r.aosChallenge(prefix, ring)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// aosChallenge returns the step of an AOS signature,
+// c_i+1 = HashToScalar(prefix || G*s_i + Y_i*c_i)
+func (r *RingSig) aosChallenge(prefix []byte, ring []*PublicKey) nextChallenge {
+	return func(i int, s, c curve.Scalar) curve.Scalar {
+		return r.Curve.HashToScalar(prefix, r.Curve.PointDoubleScalarMul(r.Curve.G(), s, ring[i].A, c))
+	}
+}
+
+// signRing walks the ring from the member after the signer, choosing random
+// responses, and closes it at the signer with s = alpha - c*x (mod q). first
+// computes the challenge following the signer from the nonce alpha.
+func (r *RingSig) signRing(rand io.Reader, x curve.Scalar, signer, n int, first func(alpha curve.Scalar) curve.Scalar, next nextChallenge) (curve.Scalar, []curve.Scalar, error) {
+	alpha, err := r.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := make([]curve.Scalar, n)
+	var c0 curve.Scalar
+	c := first(alpha)
+	for j := 1; j < n; j++ {
+		i := (signer + j) % n
+		if i == 0 {
+			c0 = c
+		}
+		if s[i], err = r.Curve.RandScalar(rand); err != nil {
+			return nil, nil, err
+		}
+		c = next(i, s[i], c)
+	}
+	if signer == 0 {
+		c0 = c
+	}
+	s[signer] = r.Curve.SubScalars(alpha, r.Curve.Mul(c, x))
+	return c0, s, nil
+}
+
+// verifyRing walks the ring from the first member and checks that it
+// closes on c0
+func (r *RingSig) verifyRing(c0 curve.Scalar, s []curve.Scalar, next nextChallenge) bool {
+	c := c0
+	for i := range s {
+		c = next(i, s[i], c)
+	}
+	return r.Curve.EqualScalars(c, c0)
+}
+
+// ringPrefix returns label || n || Y_1 || ... || Y_n || extra || message,
+// the input every challenge of a signature starts with. The size of the ring
+// is included so that keys cannot be moved between the ring and the message.
+func (r *RingSig) ringPrefix(label []byte, ring []*PublicKey, message []byte, extra ...curve.Point) []byte {
+	n := uint32(len(ring))
+	items := []interface{}{label, []byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}}
+	for _, pub := range ring {
+		items = append(items, pub.A)
+	}
+	for _, p := range extra {
+		items = append(items, p)
+	}
+	return curve.Append(append(items, message)...)
+}
+
+func (r *RingSig) signerIndex(sec *SecretKey, ring []*PublicKey) (int, error) {
+	if !r.isValidRing(ring) {
+		return 0, errors.New("invalid ring")
+	}
+	pub := r.Curve.PrecompScalarMul(sec.A)
+	for i := range ring {
+		if r.Curve.EqualPoints(pub, ring[i].A) {
+			return i, nil
+		}
+	}
+	return 0, errors.New("secret key does not match any public key")
+}
+
+func (r *RingSig) isValidRing(ring []*PublicKey) bool {
+	if len(ring) == 0 || int64(len(ring)) >= 1<<31 {
+		return false
+	}
+	for _, pub := range ring {
+		if pub == nil || !r.isValidPoint(pub.A) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RingSig) isWellFormedRing(c0 curve.Scalar, s []curve.Scalar, n int) bool {
+	if c0 == nil || len(s) != n {
+		return false
+	}
+	for _, si := range s {
+		if si == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode serializes the signature as c0 || s_1 || ... || s_n.
+func (sig *RingSignature) Encode() []byte {
+	items := []interface{}{sig.C0}
+	for _, s := range sig.S {
+		items = append(items, s)
+	}
+	return curve.Append(items...)
+}
+
+// DecodeRingSignature deserializes a signature serialized by
+// RingSignature.Encode. An error results if the length is wrong or if any
+// scalar is not valid.
+func (r *RingSig) DecodeRingSignature(b []byte) (*RingSignature, error) {
+	scalars, err := r.decodeScalars(b)
+	if err != nil {
+		return nil, err
+	}
+	return &RingSignature{C0: scalars[0], S: scalars[1:]}, nil
+}
+
+// decodeScalars decodes two or more concatenated scalars
+func (r *RingSig) decodeScalars(b []byte) ([]curve.Scalar, error) {
+	size := len(r.Curve.Q().Encode())
+	if len(b) < 2*size || len(b)%size != 0 {
+		return nil, ErrInvalidSignature
+	}
+	scalars := make([]curve.Scalar, len(b)/size)
+	for i := range scalars {
+		s, err := r.Curve.DecodeScalar(b[i*size : (i+1)*size])
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+		scalars[i] = s
+	}
+	return scalars, nil
+}
@@ -0,0 +1,130 @@
+package ringsig
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+var ringSizes = []int{2, 3, 4, 8, 16, 32, 64}
+
+func randomMember(c *C, n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	c.Assert(err, IsNil)
+	return int(i.Int64())
+}
+
+func (s *RingSigSuite) Test_SignRingAndVerifyRing(c *C) {
+	for _, n := range ringSizes {
+		ring := generateRing(c, n)
+		pubs := publicKeys(ring)
+		signer := ring[randomMember(c, n)]
+
+		sig, err := ringSig.SignRing(rand.Reader, signer.Sec, pubs, message)
+
+		c.Assert(err, IsNil)
+		c.Assert(sig.S, HasLen, n)
+		c.Assert(ringSig.VerifyRing(pubs, message, sig), IsNil)
+		c.Assert(ringSig.VerifyRing(pubs, []byte("other message"), sig), Equals, ErrInvalidSignature)
+
+		reordered := append([]*PublicKey{pubs[n-1]}, pubs[:n-1]...)
+		c.Assert(ringSig.VerifyRing(reordered, message, sig), Equals, ErrInvalidSignature)
+	}
+}
+
+func (s *RingSigSuite) Test_SignRingAtEveryPosition(c *C) {
+	ring := generateRing(c, 4)
+	pubs := publicKeys(ring)
+
+	for _, signer := range ring {
+		sig, err := ringSig.SignRing(rand.Reader, signer.Sec, pubs, message)
+		c.Assert(err, IsNil)
+		c.Assert(ringSig.VerifyRing(pubs, message, sig), IsNil)
+	}
+}
+
+func (s *RingSigSuite) Test_VerifyRingRejectsInvalidSignatures(c *C) {
+	ring := generateRing(c, 3)
+	pubs := publicKeys(ring)
+	outsider, err := ringSig.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	sig, err := ringSig.SignRing(rand.Reader, ring[0].Sec, pubs, message)
+	c.Assert(err, IsNil)
+
+	c.Assert(ringSig.VerifyRing(pubs[:2], message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing(append(pubs, outsider.Pub), message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing([]*PublicKey{pubs[0], outsider.Pub, pubs[2]}, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing([]*PublicKey{pubs[0], nil, pubs[2]}, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing(nil, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing(pubs, message, nil), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing(pubs, message, &RingSignature{C0: sig.C0, S: sig.S[:2]}), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyRing(pubs, message, &RingSignature{S: sig.S}), Equals, ErrInvalidSignature)
+
+	delta := testHelpers.MustCreateRandScalar()
+	for i := range sig.S {
+		responses := append(sig.S[:0:0], sig.S...)
+		responses[i] = testCurve.AddScalars(responses[i], delta)
+		c.Assert(ringSig.VerifyRing(pubs, message, &RingSignature{C0: sig.C0, S: responses}), Equals, ErrInvalidSignature)
+	}
+	c.Assert(ringSig.VerifyRing(pubs, message, &RingSignature{C0: testCurve.AddScalars(sig.C0, delta), S: sig.S}), Equals, ErrInvalidSignature)
+}
+
+func (s *RingSigSuite) Test_SignRingFailures(c *C) {
+	ring := generateRing(c, 3)
+	pubs := publicKeys(ring)
+	outsider, err := ringSig.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	_, err = ringSig.SignRing(rand.Reader, outsider.Sec, pubs, message)
+	c.Assert(err, ErrorMatches, "secret key does not match any public key")
+
+	_, err = ringSig.SignRing(rand.Reader, ring[0].Sec, nil, message)
+	c.Assert(err, ErrorMatches, "invalid ring")
+
+	_, err = ringSig.SignRing(rand.Reader, ring[0].Sec, []*PublicKey{pubs[0], {A: testCurve.Identity()}}, message)
+	c.Assert(err, ErrorMatches, "invalid ring")
+
+	_, err = ringSig.SignRing(testHelpers.FixedRandReader([]byte{0x00}), ring[0].Sec, pubs, message)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *RingSigSuite) Test_SignRingWithOneMember(c *C) {
+	ring := generateRing(c, 1)
+	pubs := publicKeys(ring)
+
+	sig, err := ringSig.SignRing(rand.Reader, ring[0].Sec, pubs, message)
+
+	c.Assert(err, IsNil)
+	c.Assert(ringSig.VerifyRing(pubs, message, sig), IsNil)
+}
+
+func (s *RingSigSuite) Test_EncodeAndDecodeRingSignature(c *C) {
+	ring := generateRing(c, 5)
+	pubs := publicKeys(ring)
+	sig, err := ringSig.SignRing(rand.Reader, ring[3].Sec, pubs, message)
+	c.Assert(err, IsNil)
+
+	encoded := sig.Encode()
+	c.Assert(encoded, HasLen, 6*56)
+
+	decoded, err := ringSig.DecodeRingSignature(encoded)
+	c.Assert(err, IsNil)
+	c.Assert(ringSig.VerifyRing(pubs, message, decoded), IsNil)
+
+	_, err = ringSig.DecodeRingSignature(encoded[1:])
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	_, err = ringSig.DecodeRingSignature(encoded[:56])
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	invalidScalar := append([]byte{}, encoded...)
+	for i := 4 * 56; i < 5*56; i++ {
+		invalidScalar[i] = 0xff
+	}
+	_, err = ringSig.DecodeRingSignature(invalidScalar)
+	c.Assert(err, Equals, ErrInvalidSignature)
+}
@@ -0,0 +1,110 @@
+package ringsig
+
+import (
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// LinkableSignature represents a bLSAG ring signature, an AOS ring signature
+// that also carries the CryptoNote key image I = Hp(Y)*x of the signer. Hp
+// hashes only the signer's key and not the ring, unlike Liu, Wei and Wong,
+// whose key images depend on the ring, so two signatures by the same key have
+// the same key image, whatever the ring or the message.
+type LinkableSignature struct {
+	KeyImage curve.Point
+	C0       curve.Scalar
+	S        []curve.Scalar
+}
+
+var lsagLabel = []byte("ringsig_lsag")
+
+// keyImageBase returns Hp(Y), the point the key image of Y is computed from
+func (r *RingSig) keyImageBase(pub *PublicKey) curve.Point {
+	return r.Curve.HashToPoint([]byte("ringsig_key_image"), pub.A)
+}
+
+// SignLinkable signs the message like SignRing, following the bLSAG scheme,
+// and includes the key image of sec so that signatures by the same member
+// can be linked with Linked without revealing who the member is. Errors can
+// result from reading random or if sec does not match any of the public
+// keys.
+func (r *RingSig) SignLinkable(rand io.Reader, sec *SecretKey, ring []*PublicKey, message []byte) (*LinkableSignature, error) {
+	signer, err := r.signerIndex(sec, ring)
+	if err != nil {
+		return nil, err
+	}
+	base := r.keyImageBase(ring[signer])
+	image := r.Curve.PointScalarMul(base, sec.A)
+	prefix := r.ringPrefix(lsagLabel, ring, message, image)
+
+	first := func(alpha curve.Scalar) curve.Scalar {
+		return r.Curve.HashToScalar(prefix, r.Curve.PrecompScalarMul(alpha), r.Curve.PointScalarMul(base, alpha))
+	}
+	next := r.linkableChallenge(prefix, ring, image)
+
+	c0, s, err := r.signRing(rand, sec.A, signer, len(ring), first, next)
+	if err != nil {
+		return nil, err
+	}
+	return &LinkableSignature{KeyImage: image, C0: c0, S: s}, nil
+}
+
+// VerifyLinkable checks that the signature was made on the message by a
+// member of the ring, given in the order used to sign, and that its key
+// image belongs to that member.
+func (r *RingSig) VerifyLinkable(ring []*PublicKey, message []byte, sig *LinkableSignature) error {
+	if !r.isValidRing(ring) || sig == nil || !r.isValidPoint(sig.KeyImage) ||
+		!r.isWellFormedRing(sig.C0, sig.S, len(ring)) {
+		return ErrInvalidSignature
+	}
+	prefix := r.ringPrefix(lsagLabel, ring, message, sig.KeyImage)
+	if !r.verifyRing(sig.C0, sig.S, r.linkableChallenge(prefix, ring, sig.KeyImage)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// linkableChallenge returns the step of a bLSAG signature,
+// c_i+1 = HashToScalar(prefix || G*s_i + Y_i*c_i || Hp(Y_i)*s_i + I*c_i)
+func (r *RingSig) linkableChallenge(prefix []byte, ring []*PublicKey, image curve.Point) nextChallenge {
+	return func(i int, s, c curve.Scalar) curve.Scalar {
+		return r.Curve.HashToScalar(prefix,
+			r.Curve.PointDoubleScalarMul(r.Curve.G(), s, ring[i].A, c),
+			r.Curve.PointDoubleScalarMul(r.keyImageBase(ring[i]), s, image, c))
+	}
+}
+
+// Linked reports whether two linkable signatures were made with the same
+// secret key. The signatures should be verified first.
+func (r *RingSig) Linked(sig1, sig2 *LinkableSignature) bool {
+	return r.Curve.EqualPoints(sig1.KeyImage, sig2.KeyImage)
+}
+
+// Encode serializes the signature as I || c0 || s_1 || ... || s_n.
+func (sig *LinkableSignature) Encode() []byte {
+	items := []interface{}{sig.KeyImage, sig.C0}
+	for _, s := range sig.S {
+		items = append(items, s)
+	}
+	return curve.Append(items...)
+}
+
+// DecodeLinkableSignature deserializes a signature serialized by
+// LinkableSignature.Encode. An error results if the length is wrong or if
+// the key image or any scalar is not valid.
+func (r *RingSig) DecodeLinkableSignature(b []byte) (*LinkableSignature, error) {
+	size := len(r.Curve.G().Encode())
+	if len(b) < size {
+		return nil, ErrInvalidSignature
+	}
+	image, err := r.Curve.DecodePointStrict(b[:size])
+	if err != nil || !r.isValidPoint(image) {
+		return nil, ErrInvalidSignature
+	}
+	scalars, err := r.decodeScalars(b[size:])
+	if err != nil {
+		return nil, err
+	}
+	return &LinkableSignature{KeyImage: image, C0: scalars[0], S: scalars[1:]}, nil
+}
@@ -0,0 +1,109 @@
+package ringsig
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *RingSigSuite) Test_SignLinkableAndVerifyLinkable(c *C) {
+	for _, n := range ringSizes {
+		ring := generateRing(c, n)
+		pubs := publicKeys(ring)
+		signer := ring[randomMember(c, n)]
+
+		sig, err := ringSig.SignLinkable(rand.Reader, signer.Sec, pubs, message)
+
+		c.Assert(err, IsNil)
+		c.Assert(sig.S, HasLen, n)
+		c.Assert(ringSig.VerifyLinkable(pubs, message, sig), IsNil)
+		c.Assert(ringSig.VerifyLinkable(pubs, []byte("other message"), sig), Equals, ErrInvalidSignature)
+
+		reordered := append([]*PublicKey{pubs[n-1]}, pubs[:n-1]...)
+		c.Assert(ringSig.VerifyLinkable(reordered, message, sig), Equals, ErrInvalidSignature)
+	}
+}
+
+func (s *RingSigSuite) Test_LinkedSignaturesShareKeyImages(c *C) {
+	ring := generateRing(c, 4)
+	pubs := publicKeys(ring)
+	otherRing := append(publicKeys(generateRing(c, 2)), ring[1].Pub)
+
+	sig1, err := ringSig.SignLinkable(rand.Reader, ring[1].Sec, pubs, message)
+	c.Assert(err, IsNil)
+	sig2, err := ringSig.SignLinkable(rand.Reader, ring[1].Sec, otherRing, []byte("other message"))
+	c.Assert(err, IsNil)
+	sig3, err := ringSig.SignLinkable(rand.Reader, ring[2].Sec, pubs, message)
+	c.Assert(err, IsNil)
+
+	c.Assert(ringSig.VerifyLinkable(otherRing, []byte("other message"), sig2), IsNil)
+	c.Assert(ringSig.Linked(sig1, sig2), Equals, true)
+	c.Assert(ringSig.Linked(sig1, sig3), Equals, false)
+}
+
+func (s *RingSigSuite) Test_VerifyLinkableRejectsForeignKeyImages(c *C) {
+	ring := generateRing(c, 3)
+	pubs := publicKeys(ring)
+
+	sig, err := ringSig.SignLinkable(rand.Reader, ring[0].Sec, pubs, message)
+	c.Assert(err, IsNil)
+	other, err := ringSig.SignLinkable(rand.Reader, ring[1].Sec, pubs, message)
+	c.Assert(err, IsNil)
+
+	swapped := &LinkableSignature{KeyImage: other.KeyImage, C0: sig.C0, S: sig.S}
+	c.Assert(ringSig.VerifyLinkable(pubs, message, swapped), Equals, ErrInvalidSignature)
+
+	identity := &LinkableSignature{KeyImage: testCurve.Identity(), C0: sig.C0, S: sig.S}
+	c.Assert(ringSig.VerifyLinkable(pubs, message, identity), Equals, ErrInvalidSignature)
+
+	c.Assert(ringSig.VerifyLinkable(pubs, message, &LinkableSignature{C0: sig.C0, S: sig.S}), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.VerifyLinkable(pubs, message, nil), Equals, ErrInvalidSignature)
+
+	delta := testHelpers.MustCreateRandScalar()
+	responses := append(sig.S[:0:0], sig.S...)
+	responses[1] = testCurve.AddScalars(responses[1], delta)
+	c.Assert(ringSig.VerifyLinkable(pubs, message, &LinkableSignature{KeyImage: sig.KeyImage, C0: sig.C0, S: responses}), Equals, ErrInvalidSignature)
+}
+
+func (s *RingSigSuite) Test_SignLinkableFailures(c *C) {
+	ring := generateRing(c, 2)
+	pubs := publicKeys(ring)
+	outsider, err := ringSig.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	_, err = ringSig.SignLinkable(rand.Reader, outsider.Sec, pubs, message)
+	c.Assert(err, ErrorMatches, "secret key does not match any public key")
+
+	_, err = ringSig.SignLinkable(rand.Reader, ring[0].Sec, []*PublicKey{}, message)
+	c.Assert(err, ErrorMatches, "invalid ring")
+
+	_, err = ringSig.SignLinkable(testHelpers.FixedRandReader([]byte{0x00}), ring[0].Sec, pubs, message)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *RingSigSuite) Test_EncodeAndDecodeLinkableSignature(c *C) {
+	ring := generateRing(c, 3)
+	pubs := publicKeys(ring)
+	sig, err := ringSig.SignLinkable(rand.Reader, ring[2].Sec, pubs, message)
+	c.Assert(err, IsNil)
+
+	encoded := sig.Encode()
+	c.Assert(encoded, HasLen, 5*56)
+
+	decoded, err := ringSig.DecodeLinkableSignature(encoded)
+	c.Assert(err, IsNil)
+	c.Assert(ringSig.VerifyLinkable(pubs, message, decoded), IsNil)
+	c.Assert(ringSig.Linked(sig, decoded), Equals, true)
+
+	_, err = ringSig.DecodeLinkableSignature(encoded[:56])
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	_, err = ringSig.DecodeLinkableSignature(encoded[:40])
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	identity := append(testCurve.Identity().Encode(), encoded[56:]...)
+	_, err = ringSig.DecodeLinkableSignature(identity)
+	c.Assert(err, Equals, ErrInvalidSignature)
+}
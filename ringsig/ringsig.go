@@ -0,0 +1,272 @@
+package ringsig
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// RingSig is an instance of ring signature schemes, proofs of knowledge of
+// the secret key of one of a ring of public keys that do not reveal which
+// one: the three-party scheme of OTRv4, and AOS and bLSAG signatures over rings
+// of any size. The three-party scheme hashes and serializes as OTRv4 does, so
+// it interoperates with other OTRv4 implementations.
+type RingSig struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for ring signatures
+type Curve interface {
+	curve.BasicCurve
+	curve.Hasher
+	curve.PointHasher
+	curve.PrecomputedMultiplier
+	curve.PointDoubleScalarMultiplier
+	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
+	curve.StrictPointDecoder
+	curve.EdDSAEncoder
+	curve.ScalarCalculator
+	curve.ScalarAdder
+	curve.ScalarMultiplier
+	curve.ScalarComparer
+	curve.ScalarDecoder
+}
+
+// PublicKey represents a ring signature public key.
+type PublicKey struct {
+	A curve.Point
+}
+
+// SecretKey represents a ring signature secret key.
+type SecretKey struct {
+	A curve.Scalar
+}
+
+// KeyPair represents a ring signature key pair.
+type KeyPair struct {
+	Pub *PublicKey
+	Sec *SecretKey
+}
+
+// Signature represents a ring signature sigma = (c1, r1, c2, r2, c3, r3),
+// with a challenge and a response for each public key of the ring.
+type Signature struct {
+	C1, R1 curve.Scalar
+	C2, R2 curve.Scalar
+	C3, R3 curve.Scalar
+}
+
+// ErrInvalidSignature is returned when a signature does not verify
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// usageAuth is the OTRv4 usage ID for the ring signature challenge
+const usageAuth = 0x1D
+
+const (
+	// scalarSize is the size of an OTRv4 scalar, one byte longer than the
+	// curve's own encoding
+	scalarSize = 57
+	// hashSize is the size of the KDF_1 output HashToScalar reduces
+	hashSize = 64
+	// reduceChunk is the size of the pieces a hash is split into for
+	// reduction. Each piece is below 2^440, so below the group order.
+	reduceChunk = 55
+)
+
+// orderEncoding is q encoded as an OTRv4 scalar
+var orderEncoding = []byte{
+	0xf3, 0x44, 0x58, 0xab, 0x92, 0xc2, 0x78, 0x23,
+	0x55, 0x8f, 0xc5, 0x8d, 0x72, 0xc2, 0x6c, 0x21,
+	0x90, 0x36, 0xd6, 0xae, 0x49, 0xdb, 0x4e, 0xc4,
+	0xe9, 0x23, 0xca, 0x7c, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x3f,
+	0x00,
+}
+
+// GenerateKeys generates a key pair of ring signature keys.
+func (r *RingSig) GenerateKeys(rand io.Reader) (*KeyPair, error) {
+	a, err := r.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		Pub: &PublicKey{A: r.Curve.PrecompScalarMul(a)},
+		Sec: &SecretKey{A: a},
+	}, nil
+}
+
+// challenge computes HashToScalar(0x1D || G || q || A1 || A2 || A3 || T1 ||
+// T2 || T3 || m) as OTRv4 specifies it: KDF_1 of 64 bytes, which is
+// SHAKE-256 prefixed with "OTRv4", reduced modulo q, over RFC 8032 point
+// encodings
+func (r *RingSig) challenge(a, t [3]curve.Point, message []byte) curve.Scalar {
+	h := sha3.NewShake256()
+	h.Write([]byte("OTRv4"))
+	h.Write([]byte{usageAuth})
+	h.Write(r.Curve.EncodeEdDSA(r.Curve.G()))
+	h.Write(orderEncoding)
+	for _, p := range [6]curve.Point{a[0], a[1], a[2], t[0], t[1], t[2]} {
+		h.Write(r.Curve.EncodeEdDSA(p))
+	}
+	h.Write(message)
+	out := make([]byte, hashSize)
+	h.Read(out)
+
+	// h mod q = h_low + h_high*2^440
+	shiftBytes := make([]byte, reduceChunk+1)
+	shiftBytes[reduceChunk] = 1
+	return r.Curve.AddScalars(r.smallScalar(out[:reduceChunk]),
+		r.Curve.Mul(r.smallScalar(out[reduceChunk:]), r.smallScalar(shiftBytes)))
+}
+
+// smallScalar decodes up to 56 little-endian bytes of a value below the
+// group order as a scalar
+func (r *RingSig) smallScalar(b []byte) curve.Scalar {
+	padded := make([]byte, scalarSize-1)
+	copy(padded, b)
+	s, err := r.Curve.DecodeScalar(padded)
+	if err != nil {
+		panic("programmer error: cannot decode a small scalar")
+	}
+	return s
+}
+
+// Sign computes RSig(A1, A2, A3, m), proving knowledge of the secret key of
+// one of the three public keys. The public key of sec must be one of them,
+// in any position; the signature does not reveal which. Errors can result
+// from reading random or if sec does not match any of the public keys.
+func (r *RingSig) Sign(rand io.Reader, sec *SecretKey, a1, a2, a3 *PublicKey, message []byte) (*Signature, error) {
+	a, err := r.ring(a1, a2, a3)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := -1
+	pub := r.Curve.PrecompScalarMul(sec.A)
+	for i := range a {
+		if r.Curve.EqualPoints(pub, a[i]) {
+			signer = i
+		}
+	}
+	if signer < 0 {
+		return nil, errors.New("secret key does not match any public key")
+	}
+
+	// The other positions are simulated: Tj = G*rj + Aj*cj for random cj, rj
+	var c, s [3]curve.Scalar
+	var t [3]curve.Point
+	for i := range a {
+		if c[i], err = r.Curve.RandScalar(rand); err != nil {
+			return nil, err
+		}
+		if s[i], err = r.Curve.RandScalar(rand); err != nil {
+			return nil, err
+		}
+		t[i] = r.Curve.PointDoubleScalarMul(r.Curve.G(), s[i], a[i], c[i])
+	}
+
+	// The signer's position is proven: Ti = G*ti, ci = c - cj - ck,
+	// ri = ti - ci*ai (mod q)
+	nonce := s[signer]
+	t[signer] = r.Curve.PrecompScalarMul(nonce)
+	c[signer] = r.challenge(a, t, message)
+	for i := range a {
+		if i != signer {
+			c[signer] = r.Curve.SubScalars(c[signer], c[i])
+		}
+	}
+	s[signer] = r.Curve.SubScalars(nonce, r.Curve.Mul(c[signer], sec.A))
+
+	return &Signature{
+		C1: c[0], R1: s[0],
+		C2: c[1], R2: s[1],
+		C3: c[2], R3: s[2],
+	}, nil
+}
+
+// Verify computes RVrf({A1, A2, A3}, sigma, m), checking that the signature
+// was made on the message with the secret key of one of the public keys,
+// given in the order used to sign.
+func (r *RingSig) Verify(a1, a2, a3 *PublicKey, message []byte, sig *Signature) error {
+	a, err := r.ring(a1, a2, a3)
+	if err != nil || !r.isWellFormed(sig) {
+		return ErrInvalidSignature
+	}
+	c := [3]curve.Scalar{sig.C1, sig.C2, sig.C3}
+	s := [3]curve.Scalar{sig.R1, sig.R2, sig.R3}
+
+	// Ti = G*ri + Ai*ci, c == c1 + c2 + c3 (mod q)
+	var t [3]curve.Point
+	for i := range a {
+		t[i] = r.Curve.PointDoubleScalarMul(r.Curve.G(), s[i], a[i], c[i])
+	}
+	sum := r.Curve.AddScalars(r.Curve.AddScalars(c[0], c[1]), c[2])
+	if !r.Curve.EqualScalars(r.challenge(a, t, message), sum) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (r *RingSig) ring(a1, a2, a3 *PublicKey) ([3]curve.Point, error) {
+	var a [3]curve.Point
+	for i, pub := range []*PublicKey{a1, a2, a3} {
+		if pub == nil || !r.isValidPoint(pub.A) {
+			return a, errors.New("invalid public key")
+		}
+		a[i] = pub.A
+	}
+	return a, nil
+}
+
+func (r *RingSig) isValidPoint(p curve.Point) bool {
+	return p != nil && r.Curve.IsOnCurve(p) && !r.Curve.EqualPoints(p, r.Curve.Identity())
+}
+
+func (r *RingSig) isWellFormed(sig *Signature) bool {
+	return sig != nil && sig.C1 != nil && sig.R1 != nil &&
+		sig.C2 != nil && sig.R2 != nil && sig.C3 != nil && sig.R3 != nil
+}
+
+// Encode serializes the signature as c1 || r1 || c2 || r2 || c3 || r3, each
+// as a 57-byte OTRv4 scalar.
+func (sig *Signature) Encode() []byte {
+	b := make([]byte, 0, 6*scalarSize)
+	for _, s := range []curve.Scalar{sig.C1, sig.R1, sig.C2, sig.R2, sig.C3, sig.R3} {
+		padded := make([]byte, scalarSize)
+		copy(padded, s.Encode())
+		b = append(b, padded...)
+	}
+	return b
+}
+
+// DecodeSignature deserializes a signature serialized by Signature.Encode.
+// An error results if the length is wrong or if any scalar is not valid.
+func (r *RingSig) DecodeSignature(b []byte) (*Signature, error) {
+	if len(b) != 6*scalarSize {
+		return nil, ErrInvalidSignature
+	}
+	var s [6]curve.Scalar
+	for i := range s {
+		scalar := b[i*scalarSize : (i+1)*scalarSize]
+		if scalar[scalarSize-1] != 0 {
+			return nil, ErrInvalidSignature
+		}
+		sc, err := r.Curve.DecodeScalar(scalar[:scalarSize-1])
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+		s[i] = sc
+	}
+	return &Signature{
+		C1: s[0], R1: s[1],
+		C2: s[2], R2: s[3],
+		C3: s[4], R3: s[5],
+	}, nil
+}
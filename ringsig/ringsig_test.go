@@ -0,0 +1,184 @@
+package ringsig
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type RingSigSuite struct{}
+
+var _ = Suite(&RingSigSuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	ringSig   = &RingSig{Curve: testCurve}
+	message   = []byte("our message")
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func generateRing(c *C, n int) []*KeyPair {
+	ring := make([]*KeyPair, n)
+	for i := range ring {
+		keyPair, err := ringSig.GenerateKeys(rand.Reader)
+		c.Assert(err, IsNil)
+		ring[i] = keyPair
+	}
+	return ring
+}
+
+func publicKeys(ring []*KeyPair) []*PublicKey {
+	pubs := make([]*PublicKey, len(ring))
+	for i, keyPair := range ring {
+		pubs[i] = keyPair.Pub
+	}
+	return pubs
+}
+
+func (s *RingSigSuite) Test_SignAndVerifyInEveryPosition(c *C) {
+	ring := generateRing(c, 3)
+
+	for _, signer := range ring {
+		sig, err := ringSig.Sign(rand.Reader, signer.Sec, ring[0].Pub, ring[1].Pub, ring[2].Pub, message)
+
+		c.Assert(err, IsNil)
+		c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, ring[2].Pub, message, sig), IsNil)
+		c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, ring[2].Pub, []byte("other message"), sig), Equals, ErrInvalidSignature)
+		c.Assert(ringSig.Verify(ring[1].Pub, ring[0].Pub, ring[2].Pub, message, sig), Equals, ErrInvalidSignature)
+	}
+}
+
+func (s *RingSigSuite) Test_VerifyRejectsOtherRings(c *C) {
+	ring := generateRing(c, 3)
+	outsider, err := ringSig.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	sig, err := ringSig.Sign(rand.Reader, ring[1].Sec, ring[0].Pub, ring[1].Pub, ring[2].Pub, message)
+	c.Assert(err, IsNil)
+
+	c.Assert(ringSig.Verify(ring[0].Pub, outsider.Pub, ring[2].Pub, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, &PublicKey{A: testCurve.Identity()}, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, nil, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, ring[2].Pub, message, nil), Equals, ErrInvalidSignature)
+	c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, ring[2].Pub, message, &Signature{C1: sig.C1}), Equals, ErrInvalidSignature)
+}
+
+func (s *RingSigSuite) Test_VerifyRejectsTamperedSignatures(c *C) {
+	ring := generateRing(c, 3)
+	sig, err := ringSig.Sign(rand.Reader, ring[2].Sec, ring[0].Pub, ring[1].Pub, ring[2].Pub, message)
+	c.Assert(err, IsNil)
+
+	delta := testHelpers.MustCreateRandScalar()
+	tampered := []*Signature{
+		{C1: testCurve.AddScalars(sig.C1, delta), R1: sig.R1, C2: sig.C2, R2: sig.R2, C3: sig.C3, R3: sig.R3},
+		{C1: sig.C1, R1: testCurve.AddScalars(sig.R1, delta), C2: sig.C2, R2: sig.R2, C3: sig.C3, R3: sig.R3},
+		{C1: sig.C1, R1: sig.R1, C2: sig.C2, R2: sig.R2, C3: sig.C3, R3: testCurve.AddScalars(sig.R3, delta)},
+		// moving challenge weight between positions keeps the sum but breaks
+		// the commitments
+		{C1: testCurve.AddScalars(sig.C1, delta), R1: sig.R1, C2: testCurve.SubScalars(sig.C2, delta), R2: sig.R2, C3: sig.C3, R3: sig.R3},
+	}
+	for _, t := range tampered {
+		c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, ring[2].Pub, message, t), Equals, ErrInvalidSignature)
+	}
+}
+
+func (s *RingSigSuite) Test_SignFailures(c *C) {
+	ring := generateRing(c, 3)
+	outsider, err := ringSig.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	_, err = ringSig.Sign(rand.Reader, outsider.Sec, ring[0].Pub, ring[1].Pub, ring[2].Pub, message)
+	c.Assert(err, ErrorMatches, "secret key does not match any public key")
+
+	_, err = ringSig.Sign(rand.Reader, ring[0].Sec, ring[0].Pub, ring[1].Pub, nil, message)
+	c.Assert(err, ErrorMatches, "invalid public key")
+
+	_, err = ringSig.Sign(testHelpers.FixedRandReader([]byte{0x00}), ring[0].Sec, ring[0].Pub, ring[1].Pub, ring[2].Pub, message)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, err = ringSig.GenerateKeys(testHelpers.FixedRandReader([]byte{0x00}))
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *RingSigSuite) Test_EncodeAndDecodeSignature(c *C) {
+	ring := generateRing(c, 3)
+	sig, err := ringSig.Sign(rand.Reader, ring[0].Sec, ring[0].Pub, ring[1].Pub, ring[2].Pub, message)
+	c.Assert(err, IsNil)
+
+	encoded := sig.Encode()
+	c.Assert(encoded, HasLen, 6*57)
+
+	decoded, err := ringSig.DecodeSignature(encoded)
+	c.Assert(err, IsNil)
+	c.Assert(ringSig.Verify(ring[0].Pub, ring[1].Pub, ring[2].Pub, message, decoded), IsNil)
+
+	_, err = ringSig.DecodeSignature(encoded[1:])
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	invalidScalar := append([]byte{}, encoded...)
+	for i := 2 * 57; i < 3*57-1; i++ {
+		invalidScalar[i] = 0xff
+	}
+	_, err = ringSig.DecodeSignature(invalidScalar)
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	invalidPadding := append([]byte{}, encoded...)
+	invalidPadding[3*57-1] = 0x01
+	_, err = ringSig.DecodeSignature(invalidPadding)
+	c.Assert(err, Equals, ErrInvalidSignature)
+}
+
+func smallKeyPair(c *C, a byte) *KeyPair {
+	b := make([]byte, 56)
+	b[0] = a
+	sec, err := testCurve.DecodeScalar(b)
+	c.Assert(err, IsNil)
+	return &KeyPair{
+		Pub: &PublicKey{A: testCurve.PrecompScalarMul(sec)},
+		Sec: &SecretKey{A: sec},
+	}
+}
+
+// signatureVector was computed independently from the OTRv4 specification,
+// for the secret keys 2, 5 and 7, signing with 5, and the random scalars of
+// Test_SignatureMatchesVector
+var signatureVector = decodeHex(
+	"0724415e7b98b5d2ef0c294663809dbad7f4112e4b6885a2bfdcf91633506d8aa7c4e1fe1b3855728facc9e603203d5a7794b1ceeb082500" +
+		"005f7c99b6d3f00d2a4764819ebbd8f5122f4c6986a3c0ddfa1734516e8ba8c5e2ff1c39567390adcae704213e5b7895b2cfec092643607d00" +
+		"0022ce3d9831fa2762eb7c6bf0b876e2338415aaa7fcd63874bccd0f8286b0eaa41baeab2dcf3066b3378ca23390f96e9a523ebb34f9fb7c3700" +
+		"247ecdc5688a51a10871f3600b0359661ba2f659d485fb3aaa93a50398e5df5925668f22174c5ef980f7a4ec3a481a5ee26411cf1525bc2a00" +
+		"6784a1bedbf815324f6c89a6c3e0fd1a3754718eabc8e5021f3c597693b0cdea0724415e7b98b5d2ef0c294663809dbad7f4112e4b68850000" +
+		"bfdcf91633506d8aa7c4e1fe1b3855728facc9e603203d5a7794b1ceeb0825425f7c99b6d3f00d2a4764819ebbd8f5122f4c6986a3c0dd0000")
+
+func (s *RingSigSuite) Test_SignatureMatchesVector(c *C) {
+	a1, a2, a3 := smallKeyPair(c, 2), smallKeyPair(c, 5), smallKeyPair(c, 7)
+	// six random scalars below 2^440
+	random := make([]byte, 6*56)
+	for i := range random {
+		if i%56 != 55 {
+			random[i] = byte(i*29 + 7)
+		}
+	}
+
+	sig, err := ringSig.Sign(testHelpers.FixedRandReader(random), a2.Sec, a1.Pub, a2.Pub, a3.Pub, message)
+
+	c.Assert(err, IsNil)
+	c.Assert(sig.Encode(), DeepEquals, signatureVector)
+	decoded, err := ringSig.DecodeSignature(signatureVector)
+	c.Assert(err, IsNil)
+	c.Assert(ringSig.Verify(a1.Pub, a2.Pub, a3.Pub, message, decoded), IsNil)
+}
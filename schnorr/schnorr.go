@@ -0,0 +1,208 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// Schnorr is an instance of the Schnorr signature scheme
+type Schnorr struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for Schnorr signatures
+type Curve interface {
+	curve.BasicCurve
+	curve.Hasher
+	curve.PrecomputedMultiplier
+	curve.PointDoubleScalarMultiplier
+	curve.PointCalculator
+	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
+	curve.StrictPointDecoder
+	curve.ScalarCalculator
+	curve.ScalarAdder
+	curve.ScalarMultiplier
+	curve.ScalarDecoder
+}
+
+// PublicKey represents a Schnorr public key.
+type PublicKey struct {
+	Y curve.Point
+}
+
+// SecretKey represents a Schnorr private key.
+type SecretKey struct {
+	X curve.Scalar
+}
+
+// KeyPair represents a Schnorr key pair.
+type KeyPair struct {
+	Pub *PublicKey
+	Sec *SecretKey
+}
+
+// Signature represents a Schnorr signature (R, s), where R = G*k and
+// s = k + e*x for the challenge e.
+type Signature struct {
+	R curve.Point
+	S curve.Scalar
+}
+
+// ErrInvalidSignature is returned when a signature does not verify
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// GenerateKeys generates a key pair of Schnorr keys.
+func (s *Schnorr) GenerateKeys(rand io.Reader) (*KeyPair, error) {
+	x, err := s.Curve.RandLongTermScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		Pub: &PublicKey{Y: s.Curve.PrecompScalarMul(x)},
+		Sec: &SecretKey{X: x},
+	}, nil
+}
+
+func (s *Schnorr) challenge(r, y curve.Point, message []byte) curve.Scalar {
+	// e = HashToScalar("schnorr_challenge" || R || Y || message)
+	return s.Curve.HashToScalar([]byte("schnorr_challenge"), r, y, message)
+}
+
+// Sign signs the message with the given secret key, using a nonce read from
+// rand. Errors can result from reading random.
+func (s *Schnorr) Sign(rand io.Reader, sec *SecretKey, message []byte) (*Signature, error) {
+	k, err := s.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return s.sign(k, sec, message), nil
+}
+
+// SignDeterministic signs the message with the given secret key, deriving the
+// nonce by hashing the secret key and the message, so no random is needed and
+// signing the same message twice gives the same signature.
+func (s *Schnorr) SignDeterministic(sec *SecretKey, message []byte) *Signature {
+	// k = HashToScalar("schnorr_nonce" || x || message)
+	k := s.Curve.HashToScalar([]byte("schnorr_nonce"), sec.X, message)
+	return s.sign(k, sec, message)
+}
+
+func (s *Schnorr) sign(k curve.Scalar, sec *SecretKey, message []byte) *Signature {
+	// R = G*k, s = k + e*x (mod q)
+	r := s.Curve.PrecompScalarMul(k)
+	e := s.challenge(r, s.Curve.PrecompScalarMul(sec.X), message)
+	return &Signature{
+		R: r,
+		S: s.Curve.AddScalars(k, s.Curve.Mul(e, sec.X)),
+	}
+}
+
+func (s *Schnorr) isValidPoint(p curve.Point) bool {
+	return p != nil && s.Curve.IsOnCurve(p) && !s.Curve.EqualPoints(p, s.Curve.Identity())
+}
+
+func (s *Schnorr) isWellFormed(pub *PublicKey, sig *Signature) bool {
+	return pub != nil && sig != nil && sig.S != nil && s.isValidPoint(pub.Y) && s.isValidPoint(sig.R)
+}
+
+func (s *Schnorr) zero() curve.Scalar {
+	zero, err := s.Curve.DecodeScalar(make([]byte, len(s.Curve.Q().Encode())))
+	if err != nil {
+		panic("programmer error: cannot decode a small scalar")
+	}
+	return zero
+}
+
+// Verify checks that the signature was made on the message with the secret
+// key matching the given public key.
+func (s *Schnorr) Verify(pub *PublicKey, message []byte, sig *Signature) error {
+	if !s.isWellFormed(pub, sig) {
+		return ErrInvalidSignature
+	}
+
+	// G*s - Y*e == R
+	e := s.challenge(sig.R, pub.Y, message)
+	r := s.Curve.PointDoubleScalarMul(s.Curve.G(), sig.S, pub.Y, s.Curve.SubScalars(s.zero(), e))
+	if !s.Curve.EqualPoints(r, sig.R) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// BatchVerify checks many signatures at once, which is faster than checking
+// them one by one. With random blinding scalars zi it checks
+//
+//	G*sum(zi*si) - sum(Ri*zi) - sum(Yi*(zi*ei)) == 0
+//
+// using a multi-scalar multiplication. It returns ErrInvalidSignature if any
+// signature is invalid, without saying which; use Verify to find it. If the
+// curve is not a curve.MultiScalarMultiplier every signature is checked on
+// its own.
+func (s *Schnorr) BatchVerify(pubs []*PublicKey, messages [][]byte, sigs []*Signature) error {
+	if len(pubs) != len(sigs) || len(messages) != len(sigs) {
+		return ErrInvalidSignature
+	}
+
+	msm, ok := s.Curve.(curve.MultiScalarMultiplier)
+	if !ok {
+		for i := range sigs {
+			if err := s.Verify(pubs[i], messages[i], sigs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	zero := s.zero()
+	sum := zero
+	points := []curve.Point{s.Curve.G()}
+	scalars := []curve.Scalar{nil}
+	for i, sig := range sigs {
+		if !s.isWellFormed(pubs[i], sig) {
+			return ErrInvalidSignature
+		}
+		z, err := s.Curve.RandScalar(rand.Reader)
+		if err != nil {
+			return err
+		}
+		e := s.challenge(sig.R, pubs[i].Y, messages[i])
+
+		sum = s.Curve.AddScalars(sum, s.Curve.Mul(z, sig.S))
+		points = append(points, sig.R, pubs[i].Y)
+		scalars = append(scalars, s.Curve.SubScalars(zero, z), s.Curve.SubScalars(zero, s.Curve.Mul(z, e)))
+	}
+	scalars[0] = sum
+
+	if !s.Curve.EqualPoints(msm.MultiScalarMul(points, scalars), s.Curve.Identity()) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Encode serializes the signature as R || s.
+func (sig *Signature) Encode() []byte {
+	return curve.Append(sig.R, sig.S)
+}
+
+// DecodeSignature deserializes a signature serialized by Signature.Encode.
+// An error results if the length is wrong or if R or s are not valid.
+func (s *Schnorr) DecodeSignature(b []byte) (*Signature, error) {
+	size := len(s.Curve.G().Encode())
+	if len(b) != size+len(s.Curve.Q().Encode()) {
+		return nil, ErrInvalidSignature
+	}
+	r, err := s.Curve.DecodePointStrict(b[:size])
+	if err != nil || !s.isValidPoint(r) {
+		return nil, ErrInvalidSignature
+	}
+	sc, err := s.Curve.DecodeScalar(b[size:])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &Signature{R: r, S: sc}, nil
+}
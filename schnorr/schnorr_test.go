@@ -0,0 +1,132 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SchnorrSuite struct{}
+
+var _ = Suite(&SchnorrSuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	schnorr   = &Schnorr{Curve: testCurve}
+	message   = []byte("our message")
+)
+
+func (s *SchnorrSuite) Test_SignAndVerify(c *C) {
+	keyPair, err := schnorr.GenerateKeys(rand.Reader)
+	otherKeyPair, err := schnorr.GenerateKeys(rand.Reader)
+
+	sig, err := schnorr.Sign(rand.Reader, keyPair.Sec, message)
+
+	c.Assert(err, IsNil)
+	c.Assert(schnorr.Verify(keyPair.Pub, message, sig), IsNil)
+	c.Assert(schnorr.Verify(keyPair.Pub, []byte("other message"), sig), Equals, ErrInvalidSignature)
+	c.Assert(schnorr.Verify(otherKeyPair.Pub, message, sig), Equals, ErrInvalidSignature)
+	c.Assert(schnorr.Verify(keyPair.Pub, message, &Signature{R: testCurve.G(), S: sig.S}), Equals, ErrInvalidSignature)
+	c.Assert(schnorr.Verify(keyPair.Pub, message, &Signature{R: sig.R}), Equals, ErrInvalidSignature)
+	c.Assert(schnorr.Verify(keyPair.Pub, message, nil), Equals, ErrInvalidSignature)
+
+	_, err = schnorr.Sign(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Sec, message)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+
+	_, err = schnorr.GenerateKeys(testHelpers.FixedRandReader([]byte{0x00}))
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *SchnorrSuite) Test_SignDeterministic(c *C) {
+	keyPair, err := schnorr.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+
+	sig := schnorr.SignDeterministic(keyPair.Sec, message)
+
+	c.Assert(schnorr.Verify(keyPair.Pub, message, sig), IsNil)
+	c.Assert(schnorr.SignDeterministic(keyPair.Sec, message).Encode(), DeepEquals, sig.Encode())
+	c.Assert(schnorr.SignDeterministic(keyPair.Sec, []byte("other message")).Encode(), Not(DeepEquals), sig.Encode())
+}
+
+func (s *SchnorrSuite) Test_EncodeAndDecodeSignature(c *C) {
+	keyPair, err := schnorr.GenerateKeys(rand.Reader)
+	sig, err := schnorr.Sign(rand.Reader, keyPair.Sec, message)
+
+	encoded := sig.Encode()
+	c.Assert(encoded, HasLen, 112)
+
+	decoded, err := schnorr.DecodeSignature(encoded)
+	c.Assert(err, IsNil)
+	c.Assert(schnorr.Verify(keyPair.Pub, message, decoded), IsNil)
+
+	_, err = schnorr.DecodeSignature(encoded[1:])
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	invalidScalar := append([]byte{}, encoded...)
+	for i := 56; i < 112; i++ {
+		invalidScalar[i] = 0xff
+	}
+	_, err = schnorr.DecodeSignature(invalidScalar)
+	c.Assert(err, Equals, ErrInvalidSignature)
+
+	identity := append(testCurve.Identity().Encode(), encoded[56:]...)
+	_, err = schnorr.DecodeSignature(identity)
+	c.Assert(err, Equals, ErrInvalidSignature)
+}
+
+func signMany(n int) ([]*PublicKey, [][]byte, []*Signature) {
+	pubs := make([]*PublicKey, n)
+	messages := make([][]byte, n)
+	sigs := make([]*Signature, n)
+	for i := range sigs {
+		keyPair, _ := schnorr.GenerateKeys(rand.Reader)
+		pubs[i] = keyPair.Pub
+		messages[i] = []byte{byte(i)}
+		sigs[i], _ = schnorr.Sign(rand.Reader, keyPair.Sec, messages[i])
+	}
+	return pubs, messages, sigs
+}
+
+func (s *SchnorrSuite) Test_BatchVerify(c *C) {
+	pubs, messages, sigs := signMany(8)
+
+	c.Assert(schnorr.BatchVerify(pubs, messages, sigs), IsNil)
+	c.Assert(schnorr.BatchVerify(nil, nil, nil), IsNil)
+
+	c.Assert(schnorr.BatchVerify(pubs[1:], messages, sigs), Equals, ErrInvalidSignature)
+
+	swapped := append([][]byte{}, messages...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	c.Assert(schnorr.BatchVerify(pubs, swapped, sigs), Equals, ErrInvalidSignature)
+
+	forged := append([]*Signature{}, sigs...)
+	forged[3] = &Signature{R: sigs[3].R, S: sigs[4].S}
+	c.Assert(schnorr.BatchVerify(pubs, messages, forged), Equals, ErrInvalidSignature)
+
+	forged[3] = nil
+	c.Assert(schnorr.BatchVerify(pubs, messages, forged), Equals, ErrInvalidSignature)
+}
+
+func (s *SchnorrSuite) Benchmark_VerifyLoop(c *C) {
+	pubs, messages, sigs := signMany(16)
+	c.ResetTimer()
+	for n := 0; n < c.N; n++ {
+		for i := range sigs {
+			schnorr.Verify(pubs[i], messages[i], sigs[i])
+		}
+	}
+}
+
+func (s *SchnorrSuite) Benchmark_BatchVerify(c *C) {
+	pubs, messages, sigs := signMany(16)
+	c.ResetTimer()
+	for n := 0; n < c.N; n++ {
+		schnorr.BatchVerify(pubs, messages, sigs)
+	}
+}
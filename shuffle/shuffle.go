@@ -0,0 +1,449 @@
+package shuffle
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/elgamal"
+)
+
+// Shuffler re-randomizes and permutes ElGamal ciphertexts, and proves that it
+// did so correctly, using the proof of a shuffle of Terelius and Wikström.
+// The algorithms follow "Pseudo-Code Algorithms for Verifiable
+// Re-Encryption Mix-Nets", Haenni et al., written additively.
+type Shuffler struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for the verifiable shuffle
+type Curve interface {
+	elgamal.Curve
+	curve.PointHasher
+}
+
+// Proof is a non-interactive proof that a list of ciphertexts is a
+// re-randomized permutation of another list.
+type Proof struct {
+	// Commitments is the commitment to the permutation
+	Commitments []curve.Point
+	// Chain is the chain of commitments to the permuted challenges
+	Chain []curve.Point
+
+	Challenge      curve.Scalar
+	S1, S2, S3, S4 curve.Scalar
+	SHat, SPrime   []curve.Scalar
+}
+
+// ErrInvalidProof is returned when a shuffle proof does not verify
+var ErrInvalidProof = errors.New("invalid shuffle proof")
+
+// Shuffle re-randomizes the given ciphertexts and returns them in a random
+// order, with a proof that this was done correctly. Ciphertexts are accepted
+// on the same terms as elgamal.DecodeCiphertext, so either point can be the
+// identity. Errors can result from reading random, if no ciphertexts are
+// given or if any ciphertext is invalid.
+func (s *Shuffler) Shuffle(rand io.Reader, pub *elgamal.PublicKey, in []*elgamal.Ciphertext) ([]*elgamal.Ciphertext, *Proof, error) {
+	n := len(in)
+	if n == 0 {
+		return nil, nil, errors.New("no ciphertexts to shuffle")
+	}
+	if !s.areValidCiphertexts(in) {
+		return nil, nil, errors.New("invalid ciphertext")
+	}
+
+	perm, err := randomPermutation(rand, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	reRand, err := s.randScalars(rand, n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// out_i = in_perm(i) + (G*r'_i, Y*r'_i)
+	out := make([]*elgamal.Ciphertext, n)
+	for i := range out {
+		out[i] = &elgamal.Ciphertext{
+			C1: s.Curve.AddPoints(in[perm[i]].C1, s.Curve.PrecompScalarMul(reRand[i])),
+			C2: s.Curve.AddPoints(in[perm[i]].C2, s.Curve.PointScalarMul(pub.Y, reRand[i])),
+		}
+	}
+
+	proof, err := s.prove(rand, pub, in, out, perm, reRand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, proof, nil
+}
+
+func (s *Shuffler) prove(rand io.Reader, pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext, perm []int, reRand []curve.Scalar) (*Proof, error) {
+	n := len(in)
+	h, hs := s.generators(n)
+
+	// c_perm(i) = G*r_perm(i) + h_i
+	r, err := s.randScalars(rand, n)
+	if err != nil {
+		return nil, err
+	}
+	commitments := make([]curve.Point, n)
+	for i, j := range perm {
+		commitments[j] = s.Curve.AddPoints(s.Curve.PrecompScalarMul(r[j]), hs[i])
+	}
+
+	u := s.challenges(pub, in, out, commitments)
+	uPerm := make([]curve.Scalar, n)
+	for i, j := range perm {
+		uPerm[i] = u[j]
+	}
+
+	// chain_i = G*rHat_i + chain_i-1*u'_i, chain_-1 = h
+	rHat, err := s.randScalars(rand, n)
+	if err != nil {
+		return nil, err
+	}
+	chain := make([]curve.Point, n)
+	prev := h
+	for i := range chain {
+		chain[i] = s.Curve.AddPoints(s.Curve.PrecompScalarMul(rHat[i]), s.Curve.PointScalarMul(prev, uPerm[i]))
+		prev = chain[i]
+	}
+
+	// v_n-1 = 1, v_i-1 = u'_i*v_i
+	v := make([]curve.Scalar, n)
+	v[n-1] = s.scalarOne()
+	for i := n - 1; i > 0; i-- {
+		v[i-1] = s.Curve.Mul(uPerm[i], v[i])
+	}
+
+	rBar := s.sum(r)
+	rHatSum := s.innerProduct(rHat, v)
+	rTilde := s.innerProduct(r, u)
+	rPrime := s.innerProduct(reRand, uPerm)
+
+	w, err := s.randScalars(rand, 4)
+	if err != nil {
+		return nil, err
+	}
+	wHat, err := s.randScalars(rand, n)
+	if err != nil {
+		return nil, err
+	}
+	wPrime, err := s.randScalars(rand, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// t1 = G*w1, t2 = G*w2, t3 = G*w3 + sum(h_i*w'_i)
+	// t41 = sum(out_i.c2*w'_i) - Y*w4, t42 = sum(out_i.c1*w'_i) - G*w4
+	// tHat_i = G*wHat_i + chain_i-1*w'_i
+	t1 := s.Curve.PrecompScalarMul(w[0])
+	t2 := s.Curve.PrecompScalarMul(w[1])
+	t3 := s.Curve.AddPoints(s.Curve.PrecompScalarMul(w[2]), s.linearCombination(hs, wPrime))
+	t41 := s.Curve.SubPoints(s.linearCombination(c2s(out), wPrime), s.Curve.PointScalarMul(pub.Y, w[3]))
+	t42 := s.Curve.SubPoints(s.linearCombination(c1s(out), wPrime), s.Curve.PrecompScalarMul(w[3]))
+	tHat := make([]curve.Point, n)
+	prev = h
+	for i := range tHat {
+		tHat[i] = s.Curve.AddPoints(s.Curve.PrecompScalarMul(wHat[i]), s.Curve.PointScalarMul(prev, wPrime[i]))
+		prev = chain[i]
+	}
+
+	c := s.proofChallenge(pub, in, out, commitments, chain, []curve.Point{t1, t2, t3, t41, t42}, tHat)
+
+	// s_k = w_k - c*secret_k (mod q)
+	proof := &Proof{
+		Commitments: commitments,
+		Chain:       chain,
+		Challenge:   c,
+		S1:          s.Curve.SubScalars(w[0], s.Curve.Mul(c, rBar)),
+		S2:          s.Curve.SubScalars(w[1], s.Curve.Mul(c, rHatSum)),
+		S3:          s.Curve.SubScalars(w[2], s.Curve.Mul(c, rTilde)),
+		S4:          s.Curve.SubScalars(w[3], s.Curve.Mul(c, rPrime)),
+		SHat:        make([]curve.Scalar, n),
+		SPrime:      make([]curve.Scalar, n),
+	}
+	for i := 0; i < n; i++ {
+		proof.SHat[i] = s.Curve.SubScalars(wHat[i], s.Curve.Mul(c, rHat[i]))
+		proof.SPrime[i] = s.Curve.SubScalars(wPrime[i], s.Curve.Mul(c, uPerm[i]))
+	}
+	return proof, nil
+}
+
+// Verify checks that out is a re-randomized permutation of in, for the given
+// public key. It needs no secret key and reveals nothing about the
+// permutation.
+func (s *Shuffler) Verify(pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext, proof *Proof) error {
+	n := len(in)
+	if n == 0 || len(out) != n || !s.isValidProof(proof, n) {
+		return ErrInvalidProof
+	}
+	if !s.areValidCiphertexts(in) || !s.areValidCiphertexts(out) {
+		return ErrInvalidProof
+	}
+	if pub == nil || !s.isValidPoint(pub.Y) {
+		return ErrInvalidProof
+	}
+
+	h, hs := s.generators(n)
+	u := s.challenges(pub, in, out, proof.Commitments)
+	c := proof.Challenge
+
+	// cBar = sum(c_i) - sum(h_i)
+	// cHat = chain_n-1 - h*prod(u_i)
+	// cTilde = sum(c_i*u_i), a = sum(in_i.c2*u_i), b = sum(in_i.c1*u_i)
+	cBar := s.Curve.SubPoints(s.sumPoints(proof.Commitments), s.sumPoints(hs))
+	uProd := u[0]
+	for _, ui := range u[1:] {
+		uProd = s.Curve.Mul(uProd, ui)
+	}
+	cHat := s.Curve.SubPoints(proof.Chain[n-1], s.Curve.PointScalarMul(h, uProd))
+	cTilde := s.publicCombination(proof.Commitments, u)
+	a := s.publicCombination(c2s(in), u)
+	b := s.publicCombination(c1s(in), u)
+
+	// t1 = cBar*c + G*s1
+	// t2 = cHat*c + G*s2
+	// t3 = cTilde*c + G*s3 + sum(h_i*s'_i)
+	// t41 = a*c - Y*s4 + sum(out_i.c2*s'_i)
+	// t42 = b*c - G*s4 + sum(out_i.c1*s'_i)
+	// tHat_i = chain_i*c + G*sHat_i + chain_i-1*s'_i
+	t1 := s.publicCombination([]curve.Point{cBar, s.Curve.G()}, []curve.Scalar{c, proof.S1})
+	t2 := s.publicCombination([]curve.Point{cHat, s.Curve.G()}, []curve.Scalar{c, proof.S2})
+	t3 := s.publicCombination(
+		append([]curve.Point{cTilde, s.Curve.G()}, hs...),
+		append([]curve.Scalar{c, proof.S3}, proof.SPrime...))
+	t41 := s.Curve.SubPoints(s.publicCombination(
+		append([]curve.Point{a}, c2s(out)...),
+		append([]curve.Scalar{c}, proof.SPrime...)), s.Curve.PointScalarMul(pub.Y, proof.S4))
+	t42 := s.Curve.SubPoints(s.publicCombination(
+		append([]curve.Point{b}, c1s(out)...),
+		append([]curve.Scalar{c}, proof.SPrime...)), s.Curve.PrecompScalarMul(proof.S4))
+	tHat := make([]curve.Point, n)
+	prev := h
+	for i := range tHat {
+		tHat[i] = s.publicCombination(
+			[]curve.Point{proof.Chain[i], s.Curve.G(), prev},
+			[]curve.Scalar{c, proof.SHat[i], proof.SPrime[i]})
+		prev = proof.Chain[i]
+	}
+
+	expected := s.proofChallenge(pub, in, out, proof.Commitments, proof.Chain, []curve.Point{t1, t2, t3, t41, t42}, tHat)
+	if !s.Curve.EqualScalars(c, expected) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+func (s *Shuffler) isValidProof(proof *Proof, n int) bool {
+	if proof == nil || len(proof.Commitments) != n || len(proof.Chain) != n ||
+		len(proof.SHat) != n || len(proof.SPrime) != n {
+		return false
+	}
+	for _, p := range append(append([]curve.Point{}, proof.Commitments...), proof.Chain...) {
+		if !s.isValidPoint(p) {
+			return false
+		}
+	}
+	for _, sc := range append([]curve.Scalar{proof.Challenge, proof.S1, proof.S2, proof.S3, proof.S4}, append(proof.SHat, proof.SPrime...)...) {
+		if sc == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// areValidCiphertexts accepts the ciphertexts elgamal.DecodeCiphertext
+// accepts, whose points are on the curve but can be the identity
+func (s *Shuffler) areValidCiphertexts(cts []*elgamal.Ciphertext) bool {
+	for _, ct := range cts {
+		if ct == nil || !s.isOnCurve(ct.C1) || !s.isOnCurve(ct.C2) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Shuffler) isOnCurve(p curve.Point) bool {
+	return p != nil && (s.Curve.EqualPoints(p, s.Curve.Identity()) || s.Curve.IsOnCurve(p))
+}
+
+func (s *Shuffler) isValidPoint(p curve.Point) bool {
+	return p != nil && s.Curve.IsOnCurve(p) && !s.Curve.EqualPoints(p, s.Curve.Identity())
+}
+
+// generators returns n+1 points whose discrete logarithms are unknown, h and
+// h_0 ... h_n-1
+func (s *Shuffler) generators(n int) (curve.Point, []curve.Point) {
+	h := s.Curve.HashToPoint([]byte("shuffle_generator"), indexBytes(0))
+	hs := make([]curve.Point, n)
+	for i := range hs {
+		hs[i] = s.Curve.HashToPoint([]byte("shuffle_generator"), indexBytes(i+1))
+	}
+	return h, hs
+}
+
+// challenges returns u_i = HashToScalar("shuffle_challenge" || seed || i),
+// where seed binds the public key, both lists and the permutation commitment
+func (s *Shuffler) challenges(pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext, commitments []curve.Point) []curve.Scalar {
+	items := []interface{}{[]byte("shuffle_challenge_seed"), pub.Y}
+	items = appendCiphertexts(items, in)
+	items = appendCiphertexts(items, out)
+	for _, p := range commitments {
+		items = append(items, p)
+	}
+	seed := s.Curve.HashToScalar(items...)
+
+	u := make([]curve.Scalar, len(in))
+	for i := range u {
+		u[i] = s.Curve.HashToScalar([]byte("shuffle_challenge"), seed, indexBytes(i))
+	}
+	return u
+}
+
+func (s *Shuffler) proofChallenge(pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext, commitments, chain, ts, tHat []curve.Point) curve.Scalar {
+	items := []interface{}{[]byte("shuffle_proof"), pub.Y}
+	items = appendCiphertexts(items, in)
+	items = appendCiphertexts(items, out)
+	for _, ps := range [][]curve.Point{commitments, chain, ts, tHat} {
+		for _, p := range ps {
+			items = append(items, p)
+		}
+	}
+	return s.Curve.HashToScalar(items...)
+}
+
+func appendCiphertexts(items []interface{}, cts []*elgamal.Ciphertext) []interface{} {
+	for _, ct := range cts {
+		items = append(items, ct.C1, ct.C2)
+	}
+	return items
+}
+
+func c1s(cts []*elgamal.Ciphertext) []curve.Point {
+	ps := make([]curve.Point, len(cts))
+	for i, ct := range cts {
+		ps[i] = ct.C1
+	}
+	return ps
+}
+
+func c2s(cts []*elgamal.Ciphertext) []curve.Point {
+	ps := make([]curve.Point, len(cts))
+	for i, ct := range cts {
+		ps[i] = ct.C2
+	}
+	return ps
+}
+
+func indexBytes(i int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(i))
+	return b
+}
+
+func (s *Shuffler) randScalars(rand io.Reader, n int) ([]curve.Scalar, error) {
+	ss := make([]curve.Scalar, n)
+	for i := range ss {
+		sc, err := s.Curve.RandScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		ss[i] = sc
+	}
+	return ss, nil
+}
+
+func (s *Shuffler) scalarZero() curve.Scalar {
+	zero, err := s.Curve.DecodeScalar(make([]byte, len(s.Curve.Q().Encode())))
+	if err != nil {
+		panic("programmer error: cannot decode a small scalar")
+	}
+	return zero
+}
+
+func (s *Shuffler) scalarOne() curve.Scalar {
+	b := make([]byte, len(s.Curve.Q().Encode()))
+	b[0] = 1
+	one, err := s.Curve.DecodeScalar(b)
+	if err != nil {
+		panic("programmer error: cannot decode a small scalar")
+	}
+	return one
+}
+
+func (s *Shuffler) sum(ss []curve.Scalar) curve.Scalar {
+	result := s.scalarZero()
+	for _, sc := range ss {
+		result = s.Curve.AddScalars(result, sc)
+	}
+	return result
+}
+
+func (s *Shuffler) innerProduct(a, b []curve.Scalar) curve.Scalar {
+	result := s.scalarZero()
+	for i := range a {
+		result = s.Curve.AddScalars(result, s.Curve.Mul(a[i], b[i]))
+	}
+	return result
+}
+
+func (s *Shuffler) sumPoints(ps []curve.Point) curve.Point {
+	result := s.Curve.Identity()
+	for _, p := range ps {
+		result = s.Curve.AddPoints(result, p)
+	}
+	return result
+}
+
+// linearCombination returns sum(ps_i*ss_i), one multiplication at a time, so
+// it can be used with secret scalars
+func (s *Shuffler) linearCombination(ps []curve.Point, ss []curve.Scalar) curve.Point {
+	result := s.Curve.Identity()
+	for i := range ps {
+		result = s.Curve.AddPoints(result, s.Curve.PointScalarMul(ps[i], ss[i]))
+	}
+	return result
+}
+
+// publicCombination returns sum(ps_i*ss_i) for public scalars, using a
+// multi-scalar multiplication if the curve offers one
+func (s *Shuffler) publicCombination(ps []curve.Point, ss []curve.Scalar) curve.Point {
+	if msm, ok := s.Curve.(curve.MultiScalarMultiplier); ok {
+		return msm.MultiScalarMul(ps, ss)
+	}
+	return s.linearCombination(ps, ss)
+}
+
+// randomPermutation returns a uniformly random permutation of 0 ... n-1,
+// using a Fisher-Yates shuffle
+func randomPermutation(rand io.Reader, n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := randomIndex(rand, uint64(i+1))
+		if err != nil {
+			return nil, err
+		}
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm, nil
+}
+
+// randomIndex returns a uniformly random integer in [0, n), rejecting values
+// that would bias the result
+func randomIndex(rand io.Reader, n uint64) (int, error) {
+	limit := ^uint64(0) - ^uint64(0)%n
+	b := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return 0, errors.New("cannot source enough entropy")
+		}
+		if v := binary.BigEndian.Uint64(b); v < limit {
+			return int(v % n), nil
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package shuffle
+
+import (
+	"crypto/rand"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/elgamal"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ShuffleSuite struct{}
+
+var _ = Suite(&ShuffleSuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	eg        = &elgamal.ElGamal{Curve: testCurve}
+	shuffler  = &Shuffler{Curve: testCurve}
+)
+
+func encryptMany(c *C, pub *elgamal.PublicKey, n int) ([][]byte, []*elgamal.Ciphertext) {
+	messages := make([][]byte, n)
+	cts := make([]*elgamal.Ciphertext, n)
+	for i := range cts {
+		messages[i] = testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar()).Encode()
+		ct, err := eg.EncryptCiphertext(rand.Reader, pub, messages[i])
+		c.Assert(err, IsNil)
+		cts[i] = ct
+	}
+	return messages, cts
+}
+
+func (s *ShuffleSuite) Test_ShuffleAndVerify(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	messages, in := encryptMany(c, keyPair.Pub, 5)
+
+	out, proof, err := shuffler.Shuffle(rand.Reader, keyPair.Pub, in)
+
+	c.Assert(err, IsNil)
+	c.Assert(out, HasLen, len(in))
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out, proof), IsNil)
+
+	seen := map[string]bool{}
+	for _, ct := range out {
+		seen[string(eg.DecryptCiphertext(keyPair.Sec, ct))] = true
+	}
+	for i, m := range messages {
+		c.Assert(seen[string(m)], Equals, true)
+		for _, ct := range out {
+			c.Assert(testCurve.EqualPoints(ct.C1, in[i].C1), Equals, false)
+		}
+	}
+}
+
+func (s *ShuffleSuite) Test_ShuffleSingleCiphertext(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	_, in := encryptMany(c, keyPair.Pub, 1)
+
+	out, proof, err := shuffler.Shuffle(rand.Reader, keyPair.Pub, in)
+
+	c.Assert(err, IsNil)
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out, proof), IsNil)
+
+	_, _, err = shuffler.Shuffle(rand.Reader, keyPair.Pub, nil)
+	c.Assert(err, ErrorMatches, "no ciphertexts to shuffle")
+}
+
+func (s *ShuffleSuite) Test_VerifyRejectsInvalidShuffles(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	otherKeyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	_, in := encryptMany(c, keyPair.Pub, 3)
+	out, proof, err := shuffler.Shuffle(rand.Reader, keyPair.Pub, in)
+	c.Assert(err, IsNil)
+
+	c.Assert(shuffler.Verify(otherKeyPair.Pub, in, out, proof), Equals, ErrInvalidProof)
+
+	replaced := append([]*elgamal.Ciphertext{}, out...)
+	_, extra := encryptMany(c, keyPair.Pub, 1)
+	replaced[0] = extra[0]
+	c.Assert(shuffler.Verify(keyPair.Pub, in, replaced, proof), Equals, ErrInvalidProof)
+
+	swapped := append([]*elgamal.Ciphertext{}, out...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	c.Assert(shuffler.Verify(keyPair.Pub, in, swapped, proof), Equals, ErrInvalidProof)
+
+	rerandomized := append([]*elgamal.Ciphertext{}, out...)
+	rerandomized[2], err = eg.Rerandomize(rand.Reader, keyPair.Pub, out[2])
+	c.Assert(err, IsNil)
+	c.Assert(shuffler.Verify(keyPair.Pub, in, rerandomized, proof), Equals, ErrInvalidProof)
+
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out[1:], proof), Equals, ErrInvalidProof)
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out, nil), Equals, ErrInvalidProof)
+
+	tampered := *proof
+	tampered.S1, tampered.S2 = proof.S2, proof.S1
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out, &tampered), Equals, ErrInvalidProof)
+
+	tampered = *proof
+	tampered.Chain = proof.Chain[1:]
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out, &tampered), Equals, ErrInvalidProof)
+
+	_, _, err = shuffler.Shuffle(testHelpers.FixedRandReader([]byte{0x00}), keyPair.Pub, in)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+}
+
+func (s *ShuffleSuite) Test_ShuffleAcceptsDecodedCiphertextsWithIdentityPoints(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	messages, in := encryptMany(c, keyPair.Pub, 2)
+	data, err := eg.Sub(in[0], in[0]).MarshalBinary()
+	c.Assert(err, IsNil)
+	zero, err := eg.DecodeCiphertext(data)
+	c.Assert(err, IsNil)
+	in = append(in, zero)
+
+	out, proof, err := shuffler.Shuffle(rand.Reader, keyPair.Pub, in)
+
+	c.Assert(err, IsNil)
+	c.Assert(shuffler.Verify(keyPair.Pub, in, out, proof), IsNil)
+	seen := map[string]bool{}
+	for _, ct := range out {
+		seen[string(eg.DecryptCiphertext(keyPair.Sec, ct))] = true
+	}
+	for _, m := range append(messages, testCurve.Identity().Encode()) {
+		c.Assert(seen[string(m)], Equals, true)
+	}
+}
+
+func (s *ShuffleSuite) Test_ShuffleRejectsInvalidCiphertexts(c *C) {
+	keyPair, err := eg.GenerateKeys(rand.Reader)
+	c.Assert(err, IsNil)
+	_, in := encryptMany(c, keyPair.Pub, 2)
+	out, proof, err := shuffler.Shuffle(rand.Reader, keyPair.Pub, in)
+	c.Assert(err, IsNil)
+
+	for _, invalid := range []*elgamal.Ciphertext{nil, {C1: in[0].C1}, {C2: in[0].C2}} {
+		_, _, err = shuffler.Shuffle(rand.Reader, keyPair.Pub, []*elgamal.Ciphertext{in[0], invalid})
+		c.Assert(err, ErrorMatches, "invalid ciphertext")
+		c.Assert(shuffler.Verify(keyPair.Pub, []*elgamal.Ciphertext{in[0], invalid}, out, proof), Equals, ErrInvalidProof)
+		c.Assert(shuffler.Verify(keyPair.Pub, in, []*elgamal.Ciphertext{out[0], invalid}, proof), Equals, ErrInvalidProof)
+	}
+}
+
+func (s *ShuffleSuite) Test_RandomPermutation(c *C) {
+	perm, err := randomPermutation(rand.Reader, 10)
+
+	c.Assert(err, IsNil)
+	seen := make([]bool, 10)
+	for _, i := range perm {
+		seen[i] = true
+	}
+	for _, ok := range seen {
+		c.Assert(ok, Equals, true)
+	}
+}
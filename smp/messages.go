@@ -0,0 +1,185 @@
+package smp
+
+import (
+	"github.com/twtiger/crypto/curve"
+)
+
+// Message1 is SMP message 1, sent by the initiator
+type Message1 struct {
+	Question []byte
+	G2a      curve.Point
+	C2, D2   curve.Scalar
+	G3a      curve.Point
+	C3, D3   curve.Scalar
+}
+
+// Message2 is SMP message 2, sent by the responder
+type Message2 struct {
+	G2b        curve.Point
+	C2, D2     curve.Scalar
+	G3b        curve.Point
+	C3, D3     curve.Scalar
+	Pb, Qb     curve.Point
+	CP, D5, D6 curve.Scalar
+}
+
+// Message3 is SMP message 3, sent by the initiator
+type Message3 struct {
+	Pa, Qa     curve.Point
+	CP, D5, D6 curve.Scalar
+	Ra         curve.Point
+	CR, D7     curve.Scalar
+}
+
+// Message4 is SMP message 4, sent by the responder
+type Message4 struct {
+	Rb     curve.Point
+	CR, D7 curve.Scalar
+}
+
+// Encode serializes the message as the 4-byte big-endian length of the
+// question, the question, and then G2a || c2 || D2 || G3a || c3 || D3.
+func (m *Message1) Encode() []byte {
+	n := len(m.Question)
+	return curve.Append([]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}, m.Question,
+		m.G2a, m.C2, m.D2, m.G3a, m.C3, m.D3)
+}
+
+// Encode serializes the message as
+// G2b || c2 || D2 || G3b || c3 || D3 || Pb || Qb || cP || D5 || D6.
+func (m *Message2) Encode() []byte {
+	return curve.Append(m.G2b, m.C2, m.D2, m.G3b, m.C3, m.D3, m.Pb, m.Qb, m.CP, m.D5, m.D6)
+}
+
+// Encode serializes the message as Pa || Qa || cP || D5 || D6 || Ra || cR || D7.
+func (m *Message3) Encode() []byte {
+	return curve.Append(m.Pa, m.Qa, m.CP, m.D5, m.D6, m.Ra, m.CR, m.D7)
+}
+
+// Encode serializes the message as Rb || cR || D7.
+func (m *Message4) Encode() []byte {
+	return curve.Append(m.Rb, m.CR, m.D7)
+}
+
+// decoder reads the fields of a message in order, remembering whether any
+// of them was invalid
+type decoder struct {
+	smp   *SMP
+	b     []byte
+	valid bool
+}
+
+func (s *SMP) newDecoder(b []byte) *decoder {
+	return &decoder{smp: s, b: b, valid: true}
+}
+
+func (d *decoder) next(n int) []byte {
+	if !d.valid || len(d.b) < n {
+		d.valid = false
+		return nil
+	}
+	field := d.b[:n]
+	d.b = d.b[n:]
+	return field
+}
+
+func (d *decoder) data() []byte {
+	l := d.next(4)
+	if l == nil {
+		return nil
+	}
+	n := uint32(l[0])<<24 | uint32(l[1])<<16 | uint32(l[2])<<8 | uint32(l[3])
+	if uint64(n) > uint64(len(d.b)) {
+		d.valid = false
+		return nil
+	}
+	return append([]byte{}, d.next(int(n))...)
+}
+
+func (d *decoder) point() curve.Point {
+	b := d.next(len(d.smp.Curve.G().Encode()))
+	if b == nil {
+		return nil
+	}
+	p, err := d.smp.Curve.DecodePointStrict(b)
+	if err != nil {
+		d.valid = false
+		return nil
+	}
+	return p
+}
+
+func (d *decoder) scalar() curve.Scalar {
+	b := d.next(len(d.smp.Curve.Q().Encode()))
+	if b == nil {
+		return nil
+	}
+	s, err := d.smp.Curve.DecodeScalar(b)
+	if err != nil {
+		d.valid = false
+		return nil
+	}
+	return s
+}
+
+// finish reports whether every field was valid and nothing is left over
+func (d *decoder) finish() error {
+	if !d.valid || len(d.b) != 0 {
+		return ErrInvalidMessage
+	}
+	return nil
+}
+
+// DecodeMessage1 deserializes SMP message 1 serialized by Message1.Encode.
+// An error results if the length is wrong or if any field is not valid.
+func (s *SMP) DecodeMessage1(b []byte) (*Message1, error) {
+	d := s.newDecoder(b)
+	m := &Message1{Question: d.data()}
+	m.G2a, m.C2, m.D2 = d.point(), d.scalar(), d.scalar()
+	m.G3a, m.C3, m.D3 = d.point(), d.scalar(), d.scalar()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeMessage2 deserializes SMP message 2 serialized by Message2.Encode.
+// An error results if the length is wrong or if any field is not valid.
+func (s *SMP) DecodeMessage2(b []byte) (*Message2, error) {
+	d := s.newDecoder(b)
+	m := &Message2{}
+	m.G2b, m.C2, m.D2 = d.point(), d.scalar(), d.scalar()
+	m.G3b, m.C3, m.D3 = d.point(), d.scalar(), d.scalar()
+	m.Pb, m.Qb = d.point(), d.point()
+	m.CP, m.D5, m.D6 = d.scalar(), d.scalar(), d.scalar()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeMessage3 deserializes SMP message 3 serialized by Message3.Encode.
+// An error results if the length is wrong or if any field is not valid.
+func (s *SMP) DecodeMessage3(b []byte) (*Message3, error) {
+	d := s.newDecoder(b)
+	m := &Message3{}
+	m.Pa, m.Qa = d.point(), d.point()
+	m.CP, m.D5, m.D6 = d.scalar(), d.scalar(), d.scalar()
+	m.Ra, m.CR, m.D7 = d.point(), d.scalar(), d.scalar()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeMessage4 deserializes SMP message 4 serialized by Message4.Encode.
+// An error results if the length is wrong or if any field is not valid.
+func (s *SMP) DecodeMessage4(b []byte) (*Message4, error) {
+	d := s.newDecoder(b)
+	m := &Message4{}
+	m.Rb, m.CR, m.D7 = d.point(), d.scalar(), d.scalar()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
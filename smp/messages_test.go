@@ -0,0 +1,97 @@
+package smp
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+)
+
+func messages(c *C) (*Message1, *Message2, *Message3, *Message4) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+	m1, err := alice.Start(rand.Reader, secret, question)
+	c.Assert(err, IsNil)
+	m2, err := bob.ReceiveMessage1(rand.Reader, secret, m1)
+	c.Assert(err, IsNil)
+	m3, err := alice.ReceiveMessage2(rand.Reader, m2)
+	c.Assert(err, IsNil)
+	m4, err := bob.ReceiveMessage3(rand.Reader, m3)
+	c.Assert(err, IsNil)
+	return m1, m2, m3, m4
+}
+
+func (s *SMPSuite) Test_EncodeAndDecodeMessages(c *C) {
+	m1, m2, m3, m4 := messages(c)
+
+	encoded1 := m1.Encode()
+	c.Assert(encoded1, HasLen, 4+len(question)+6*56)
+	decoded1, err := smp.DecodeMessage1(encoded1)
+	c.Assert(err, IsNil)
+	c.Assert(decoded1.Encode(), DeepEquals, encoded1)
+
+	encoded2 := m2.Encode()
+	c.Assert(encoded2, HasLen, 11*56)
+	decoded2, err := smp.DecodeMessage2(encoded2)
+	c.Assert(err, IsNil)
+	c.Assert(decoded2.Encode(), DeepEquals, encoded2)
+
+	encoded3 := m3.Encode()
+	c.Assert(encoded3, HasLen, 8*56)
+	decoded3, err := smp.DecodeMessage3(encoded3)
+	c.Assert(err, IsNil)
+	c.Assert(decoded3.Encode(), DeepEquals, encoded3)
+
+	encoded4 := m4.Encode()
+	c.Assert(encoded4, HasLen, 3*56)
+	decoded4, err := smp.DecodeMessage4(encoded4)
+	c.Assert(err, IsNil)
+	c.Assert(decoded4.Encode(), DeepEquals, encoded4)
+}
+
+func (s *SMPSuite) Test_EncodeAndDecodeMessage1WithoutQuestion(c *C) {
+	m1, _, _, _ := messages(c)
+	m1.Question = nil
+
+	decoded, err := smp.DecodeMessage1(m1.Encode())
+
+	c.Assert(err, IsNil)
+	c.Assert(decoded.Question, HasLen, 0)
+	c.Assert(decoded.Encode(), DeepEquals, m1.Encode())
+}
+
+func (s *SMPSuite) Test_DecodeRejectsWrongLengths(c *C) {
+	m1, m2, m3, m4 := messages(c)
+
+	_, err := smp.DecodeMessage1(m1.Encode()[1:])
+	c.Assert(err, Equals, ErrInvalidMessage)
+	_, err = smp.DecodeMessage1(append(m1.Encode(), 0))
+	c.Assert(err, Equals, ErrInvalidMessage)
+	_, err = smp.DecodeMessage1([]byte{0xff, 0xff, 0xff, 0xff})
+	c.Assert(err, Equals, ErrInvalidMessage)
+	_, err = smp.DecodeMessage1(nil)
+	c.Assert(err, Equals, ErrInvalidMessage)
+
+	_, err = smp.DecodeMessage2(m2.Encode()[1:])
+	c.Assert(err, Equals, ErrInvalidMessage)
+	_, err = smp.DecodeMessage3(append(m3.Encode(), 0))
+	c.Assert(err, Equals, ErrInvalidMessage)
+	_, err = smp.DecodeMessage4(m4.Encode()[:56])
+	c.Assert(err, Equals, ErrInvalidMessage)
+}
+
+func (s *SMPSuite) Test_DecodeRejectsInvalidFields(c *C) {
+	_, _, m3, m4 := messages(c)
+
+	invalidScalar := m4.Encode()
+	for i := 56; i < 2*56; i++ {
+		invalidScalar[i] = 0xff
+	}
+	_, err := smp.DecodeMessage4(invalidScalar)
+	c.Assert(err, Equals, ErrInvalidMessage)
+
+	invalidPoint := m3.Encode()
+	for i := 0; i < 56; i++ {
+		invalidPoint[i] = 0xff
+	}
+	_, err = smp.DecodeMessage3(invalidPoint)
+	c.Assert(err, Equals, ErrInvalidMessage)
+}
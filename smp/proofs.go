@@ -0,0 +1,120 @@
+package smp
+
+import (
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// usageSecret is the usage ID for hashing the user's secret, set apart from
+// the usage IDs 0x01 to 0x08 of the proofs
+const usageSecret = 0x1B
+
+// hash computes HashToScalar("OTRv4" || usage || items)
+func (s *SMP) hash(usage byte, items ...interface{}) curve.Scalar {
+	return s.Curve.HashToScalar(append([]interface{}{[]byte("OTRv4"), []byte{usage}}, items...)...)
+}
+
+func (s *SMP) randScalars(rand io.Reader, n int) ([]curve.Scalar, error) {
+	scalars := make([]curve.Scalar, n)
+	for i := range scalars {
+		r, err := s.Curve.RandScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		scalars[i] = r
+	}
+	return scalars, nil
+}
+
+func (s *SMP) isValidPoint(p curve.Point) bool {
+	return p != nil && s.Curve.IsOnCurve(p) && !s.Curve.EqualPoints(p, s.Curve.Identity())
+}
+
+func (s *SMP) arePointsValid(points ...curve.Point) bool {
+	for _, p := range points {
+		if !s.isValidPoint(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func areScalarsSet(scalars ...curve.Scalar) bool {
+	for _, sc := range scalars {
+		if sc == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// proveLog proves knowledge of x = log_G(G*x):
+// c = HashToScalar(usage || G*r), D = r - x*c
+func (s *SMP) proveLog(rand io.Reader, usage byte, x curve.Scalar) (c, d curve.Scalar, err error) {
+	r, err := s.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	c = s.hash(usage, s.Curve.PrecompScalarMul(r))
+	return c, s.Curve.SubScalars(r, s.Curve.Mul(x, c)), nil
+}
+
+// verifyLog checks a proof made by proveLog for p = G*x:
+// c == HashToScalar(usage || G*D + p*c)
+func (s *SMP) verifyLog(usage byte, p curve.Point, c, d curve.Scalar) bool {
+	if !areScalarsSet(c, d) {
+		return false
+	}
+	return s.Curve.EqualScalars(c, s.hash(usage, s.Curve.PointDoubleScalarMul(s.Curve.G(), d, p, c)))
+}
+
+// proveCoordinates proves that P = G3*r and Q = G*r + G2*secret were built
+// from the same r:
+// c = HashToScalar(usage || G3*r5 || G*r5 + G2*r6), D5 = r5 - r*c,
+// D6 = r6 - secret*c
+func (s *SMP) proveCoordinates(rand io.Reader, usage byte, g2, g3 curve.Point, r, secret curve.Scalar) (c, d5, d6 curve.Scalar, err error) {
+	rs, err := s.randScalars(rand, 2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r5, r6 := rs[0], rs[1]
+	c = s.hash(usage, s.Curve.PointScalarMul(g3, r5), s.Curve.PointDoubleScalarMul(s.Curve.G(), r5, g2, r6))
+	d5 = s.Curve.SubScalars(r5, s.Curve.Mul(r, c))
+	d6 = s.Curve.SubScalars(r6, s.Curve.Mul(secret, c))
+	return c, d5, d6, nil
+}
+
+// verifyCoordinates checks a proof made by proveCoordinates:
+// c == HashToScalar(usage || G3*D5 + P*c || G*D5 + G2*D6 + Q*c)
+func (s *SMP) verifyCoordinates(usage byte, g2, g3, p, q curve.Point, c, d5, d6 curve.Scalar) bool {
+	if !areScalarsSet(c, d5, d6) {
+		return false
+	}
+	t1 := s.Curve.PointDoubleScalarMul(g3, d5, p, c)
+	t2 := s.Curve.AddPoints(s.Curve.PointDoubleScalarMul(s.Curve.G(), d5, g2, d6), s.Curve.PointScalarMul(q, c))
+	return s.Curve.EqualScalars(c, s.hash(usage, t1, t2))
+}
+
+// proveEqualLogs proves that log_G(G3) = log_qab(R) = x3:
+// c = HashToScalar(usage || G*r7 || qab*r7), D7 = r7 - x3*c
+func (s *SMP) proveEqualLogs(rand io.Reader, usage byte, qab curve.Point, x3 curve.Scalar) (c, d7 curve.Scalar, err error) {
+	r7, err := s.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	c = s.hash(usage, s.Curve.PrecompScalarMul(r7), s.Curve.PointScalarMul(qab, r7))
+	return c, s.Curve.SubScalars(r7, s.Curve.Mul(x3, c)), nil
+}
+
+// verifyEqualLogs checks a proof made by proveEqualLogs for G3 = G*x3 and
+// R = qab*x3:
+// c == HashToScalar(usage || G*D7 + G3*c || qab*D7 + R*c)
+func (s *SMP) verifyEqualLogs(usage byte, g3, qab, r curve.Point, c, d7 curve.Scalar) bool {
+	if !areScalarsSet(c, d7) {
+		return false
+	}
+	t1 := s.Curve.PointDoubleScalarMul(s.Curve.G(), d7, g3, c)
+	t2 := s.Curve.PointDoubleScalarMul(qab, d7, r, c)
+	return s.Curve.EqualScalars(c, s.hash(usage, t1, t2))
+}
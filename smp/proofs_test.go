@@ -0,0 +1,53 @@
+package smp
+
+import (
+	"crypto/rand"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func (s *SMPSuite) Test_ProveAndVerifyLog(c *C) {
+	x := testHelpers.MustCreateRandScalar()
+	p := testCurve.PrecompScalarMul(x)
+
+	proofC, proofD, err := smp.proveLog(rand.Reader, 0x01, x)
+
+	c.Assert(err, IsNil)
+	c.Assert(smp.verifyLog(0x01, p, proofC, proofD), Equals, true)
+	c.Assert(smp.verifyLog(0x02, p, proofC, proofD), Equals, false)
+	c.Assert(smp.verifyLog(0x01, testCurve.G(), proofC, proofD), Equals, false)
+	c.Assert(smp.verifyLog(0x01, p, proofC, nil), Equals, false)
+}
+
+func (s *SMPSuite) Test_ProveAndVerifyCoordinates(c *C) {
+	g2 := testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar())
+	g3 := testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar())
+	r := testHelpers.MustCreateRandScalar()
+	y := testHelpers.MustCreateRandScalar()
+	p := testCurve.PointScalarMul(g3, r)
+	q := testCurve.PointDoubleScalarMul(testCurve.G(), r, g2, y)
+
+	proofC, d5, d6, err := smp.proveCoordinates(rand.Reader, 0x05, g2, g3, r, y)
+
+	c.Assert(err, IsNil)
+	c.Assert(smp.verifyCoordinates(0x05, g2, g3, p, q, proofC, d5, d6), Equals, true)
+	c.Assert(smp.verifyCoordinates(0x06, g2, g3, p, q, proofC, d5, d6), Equals, false)
+	c.Assert(smp.verifyCoordinates(0x05, g2, g3, q, p, proofC, d5, d6), Equals, false)
+	c.Assert(smp.verifyCoordinates(0x05, g2, g3, p, q, proofC, d6, d5), Equals, false)
+}
+
+func (s *SMPSuite) Test_ProveAndVerifyEqualLogs(c *C) {
+	x3 := testHelpers.MustCreateRandScalar()
+	g3 := testCurve.PrecompScalarMul(x3)
+	qab := testCurve.PrecompScalarMul(testHelpers.MustCreateRandScalar())
+	r := testCurve.PointScalarMul(qab, x3)
+
+	proofC, d7, err := smp.proveEqualLogs(rand.Reader, 0x07, qab, x3)
+
+	c.Assert(err, IsNil)
+	c.Assert(smp.verifyEqualLogs(0x07, g3, qab, r, proofC, d7), Equals, true)
+	c.Assert(smp.verifyEqualLogs(0x08, g3, qab, r, proofC, d7), Equals, false)
+	c.Assert(smp.verifyEqualLogs(0x07, g3, qab, testCurve.G(), proofC, d7), Equals, false)
+}
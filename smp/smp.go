@@ -0,0 +1,347 @@
+package smp
+
+import (
+	"errors"
+	"io"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// SMP is an instance of the Socialist Millionaires' Protocol of OTRv4, with
+// which two parties check whether they hold the same secret without
+// revealing anything else about it.
+//
+// SMP does not bind the secret to the conversation: as OTRv4 does, both
+// parties must pass as secret
+//
+//	initiator fingerprint || responder fingerprint || SSID || user's secret
+//
+// where the fingerprints are those of the long-term public keys of the party
+// that calls Start and of the party that calls ReceiveMessage1, and SSID is
+// the secure session id the DAKE established. Without this binding a run
+// succeeds whenever the users' secrets match, even through a man in the
+// middle.
+type SMP struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required for SMP
+type Curve interface {
+	curve.BasicCurve
+	curve.Hasher
+	curve.PrecomputedMultiplier
+	curve.PointDoubleScalarMultiplier
+	curve.PointCalculator
+	curve.PointComparer
+	curve.PointValidator
+	curve.IdentityPoint
+	curve.StrictPointDecoder
+	curve.ScalarCalculator
+	curve.ScalarMultiplier
+	curve.ScalarComparer
+	curve.ScalarDecoder
+}
+
+// State is the SMP message a session expects next
+type State int
+
+const (
+	// ExpectMessage1 is the state of a session that is not running, in which
+	// it can start or receive SMP message 1
+	ExpectMessage1 State = iota
+	// ExpectMessage2 is the state of an initiator that has sent SMP message 1
+	ExpectMessage2
+	// ExpectMessage3 is the state of a responder that has sent SMP message 2
+	ExpectMessage3
+	// ExpectMessage4 is the state of an initiator that has sent SMP message 3
+	ExpectMessage4
+)
+
+// Outcome is the outcome of the last run of a session
+type Outcome int
+
+const (
+	// InProgress means the session has not finished a run
+	InProgress Outcome = iota
+	// Succeeded means both parties hold the same secret
+	Succeeded
+	// Failed means the parties hold different secrets
+	Failed
+	// Aborted means the run was abandoned before it finished
+	Aborted
+)
+
+var (
+	// ErrUnexpectedMessage is returned when a message arrives in a state that
+	// does not expect it
+	ErrUnexpectedMessage = errors.New("unexpected SMP message")
+	// ErrInvalidMessage is returned when a message holds an invalid point or
+	// a proof that does not verify
+	ErrInvalidMessage = errors.New("invalid SMP message")
+)
+
+// Session holds one party's state across the messages of SMP runs. Any
+// error aborts the current run, leaving the session in ExpectMessage1; the
+// other party should then be sent an SMP abort. Messages 2 to 4 that arrive
+// when no run is in progress are stray and only return ErrUnexpectedMessage,
+// leaving the outcome of the last run as it was.
+type Session struct {
+	smp     *SMP
+	state   State
+	outcome Outcome
+
+	secret curve.Scalar
+	// x2 and x3 are a2 and a3 for the initiator, b2 and b3 for the responder
+	x2, x3 curve.Scalar
+	// g3o is the other party's G3a or G3b
+	g3o    curve.Point
+	g2, g3 curve.Point
+	// pa and qa are the initiator's Pa and Qa, pb and qb the responder's
+	pa, qa curve.Point
+	pb, qb curve.Point
+}
+
+// NewSession returns a session in ExpectMessage1.
+func (s *SMP) NewSession() *Session {
+	return &Session{smp: s}
+}
+
+// State returns the message the session expects next.
+func (sess *Session) State() State {
+	return sess.state
+}
+
+// Outcome returns the outcome of the last run of the session.
+func (sess *Session) Outcome() Outcome {
+	return sess.outcome
+}
+
+// Abort abandons the current run, such as when the other party sends an SMP
+// abort.
+func (sess *Session) Abort() {
+	*sess = Session{smp: sess.smp, outcome: Aborted}
+}
+
+func (sess *Session) abort(err error) error {
+	sess.Abort()
+	return err
+}
+
+// unexpected aborts a run in progress on a message it does not expect, and
+// ignores the message if no run is in progress
+func (sess *Session) unexpected() error {
+	if sess.state == ExpectMessage1 {
+		return ErrUnexpectedMessage
+	}
+	return sess.abort(ErrUnexpectedMessage)
+}
+
+func (sess *Session) finish(pa, pb, rab curve.Point) {
+	c := sess.smp.Curve
+	outcome := Failed
+	// Pa - Pb == Rab
+	if c.EqualPoints(c.SubPoints(pa, pb), rab) {
+		outcome = Succeeded
+	}
+	*sess = Session{smp: sess.smp, outcome: outcome}
+}
+
+// secretScalar hashes the secret, bound to the conversation as described on
+// SMP, into the scalar x or y, x = HashToScalar("OTRv4" || 0x1B || secret).
+func (s *SMP) secretScalar(secret []byte) curve.Scalar {
+	return s.hash(usageSecret, secret)
+}
+
+// Start begins a run as the initiator, with the secret bound to the
+// conversation as described on SMP and an optional question for the other
+// party, and returns SMP message 1. A run in progress is aborted.
+// Errors can result from reading random.
+func (sess *Session) Start(rand io.Reader, secret, question []byte) (*Message1, error) {
+	s := sess.smp
+	sess.Abort()
+
+	var err error
+	if sess.x2, err = s.Curve.RandScalar(rand); err != nil {
+		return nil, sess.abort(err)
+	}
+	if sess.x3, err = s.Curve.RandScalar(rand); err != nil {
+		return nil, sess.abort(err)
+	}
+
+	m := &Message1{
+		Question: question,
+		G2a:      s.Curve.PrecompScalarMul(sess.x2),
+		G3a:      s.Curve.PrecompScalarMul(sess.x3),
+	}
+	// c2 = HashToScalar(0x01 || G*r2), D2 = r2 - a2*c2
+	if m.C2, m.D2, err = s.proveLog(rand, 0x01, sess.x2); err != nil {
+		return nil, sess.abort(err)
+	}
+	// c3 = HashToScalar(0x02 || G*r3), D3 = r3 - a3*c3
+	if m.C3, m.D3, err = s.proveLog(rand, 0x02, sess.x3); err != nil {
+		return nil, sess.abort(err)
+	}
+
+	sess.secret = s.secretScalar(secret)
+	sess.state = ExpectMessage2
+	sess.outcome = InProgress
+	return m, nil
+}
+
+// ReceiveMessage1 checks SMP message 1 as the responder and answers with SMP
+// message 2, using the secret the user entered, bound to the conversation as
+// described on SMP, possibly after being shown the question in the message.
+// Errors can result from reading random, if the session is running, or if the
+// message is invalid.
+func (sess *Session) ReceiveMessage1(rand io.Reader, secret []byte, m *Message1) (*Message2, error) {
+	s := sess.smp
+	if sess.state != ExpectMessage1 {
+		return nil, sess.abort(ErrUnexpectedMessage)
+	}
+	if m == nil || !s.arePointsValid(m.G2a, m.G3a) ||
+		!s.verifyLog(0x01, m.G2a, m.C2, m.D2) || !s.verifyLog(0x02, m.G3a, m.C3, m.D3) {
+		return nil, sess.abort(ErrInvalidMessage)
+	}
+
+	b, err := s.randScalars(rand, 3)
+	if err != nil {
+		return nil, sess.abort(err)
+	}
+	b2, b3, r4 := b[0], b[1], b[2]
+	y := s.secretScalar(secret)
+
+	reply := &Message2{
+		G2b: s.Curve.PrecompScalarMul(b2),
+		G3b: s.Curve.PrecompScalarMul(b3),
+	}
+	// c2 = HashToScalar(0x03 || G*r2), D2 = r2 - b2*c2
+	if reply.C2, reply.D2, err = s.proveLog(rand, 0x03, b2); err != nil {
+		return nil, sess.abort(err)
+	}
+	// c3 = HashToScalar(0x04 || G*r3), D3 = r3 - b3*c3
+	if reply.C3, reply.D3, err = s.proveLog(rand, 0x04, b3); err != nil {
+		return nil, sess.abort(err)
+	}
+
+	// G2 = G2a*b2, G3 = G3a*b3, Pb = G3*r4, Qb = G*r4 + G2*y
+	g2 := s.Curve.PointScalarMul(m.G2a, b2)
+	g3 := s.Curve.PointScalarMul(m.G3a, b3)
+	reply.Pb = s.Curve.PointScalarMul(g3, r4)
+	reply.Qb = s.Curve.PointDoubleScalarMul(s.Curve.G(), r4, g2, y)
+	if reply.CP, reply.D5, reply.D6, err = s.proveCoordinates(rand, 0x05, g2, g3, r4, y); err != nil {
+		return nil, sess.abort(err)
+	}
+
+	*sess = Session{
+		smp:     s,
+		state:   ExpectMessage3,
+		outcome: InProgress,
+		secret:  y,
+		x2:      b2,
+		x3:      b3,
+		g3o:     m.G3a,
+		g2:      g2,
+		g3:      g3,
+		pb:      reply.Pb,
+		qb:      reply.Qb,
+	}
+	return reply, nil
+}
+
+// ReceiveMessage2 checks SMP message 2 as the initiator and answers with SMP
+// message 3. Errors can result from reading random, if the session does not
+// expect the message, or if the message is invalid.
+func (sess *Session) ReceiveMessage2(rand io.Reader, m *Message2) (*Message3, error) {
+	s := sess.smp
+	if sess.state != ExpectMessage2 {
+		return nil, sess.unexpected()
+	}
+	if m == nil || !s.arePointsValid(m.G2b, m.G3b, m.Pb, m.Qb) ||
+		!s.verifyLog(0x03, m.G2b, m.C2, m.D2) || !s.verifyLog(0x04, m.G3b, m.C3, m.D3) {
+		return nil, sess.abort(ErrInvalidMessage)
+	}
+
+	// G2 = G2b*a2, G3 = G3b*a3
+	g2 := s.Curve.PointScalarMul(m.G2b, sess.x2)
+	g3 := s.Curve.PointScalarMul(m.G3b, sess.x3)
+	if !s.verifyCoordinates(0x05, g2, g3, m.Pb, m.Qb, m.CP, m.D5, m.D6) {
+		return nil, sess.abort(ErrInvalidMessage)
+	}
+
+	r4, err := s.Curve.RandScalar(rand)
+	if err != nil {
+		return nil, sess.abort(err)
+	}
+
+	// Pa = G3*r4, Qa = G*r4 + G2*x, Ra = (Qa - Qb)*a3
+	reply := &Message3{
+		Pa: s.Curve.PointScalarMul(g3, r4),
+		Qa: s.Curve.PointDoubleScalarMul(s.Curve.G(), r4, g2, sess.secret),
+	}
+	if reply.CP, reply.D5, reply.D6, err = s.proveCoordinates(rand, 0x06, g2, g3, r4, sess.secret); err != nil {
+		return nil, sess.abort(err)
+	}
+	qab := s.Curve.SubPoints(reply.Qa, m.Qb)
+	reply.Ra = s.Curve.PointScalarMul(qab, sess.x3)
+	if reply.CR, reply.D7, err = s.proveEqualLogs(rand, 0x07, qab, sess.x3); err != nil {
+		return nil, sess.abort(err)
+	}
+
+	sess.state = ExpectMessage4
+	sess.g3o = m.G3b
+	sess.g2, sess.g3 = g2, g3
+	sess.pa, sess.qa = reply.Pa, reply.Qa
+	sess.pb, sess.qb = m.Pb, m.Qb
+	return reply, nil
+}
+
+// ReceiveMessage3 checks SMP message 3 as the responder, learns the result
+// of the run and answers with SMP message 4, so that the initiator learns it
+// too. Errors can result from reading random, if the session does not expect
+// the message, or if the message is invalid.
+func (sess *Session) ReceiveMessage3(rand io.Reader, m *Message3) (*Message4, error) {
+	s := sess.smp
+	if sess.state != ExpectMessage3 {
+		return nil, sess.unexpected()
+	}
+	if m == nil || !s.arePointsValid(m.Pa, m.Qa, m.Ra) ||
+		!s.verifyCoordinates(0x06, sess.g2, sess.g3, m.Pa, m.Qa, m.CP, m.D5, m.D6) {
+		return nil, sess.abort(ErrInvalidMessage)
+	}
+	qab := s.Curve.SubPoints(m.Qa, sess.qb)
+	if !s.verifyEqualLogs(0x07, sess.g3o, qab, m.Ra, m.CR, m.D7) {
+		return nil, sess.abort(ErrInvalidMessage)
+	}
+
+	// Rb = (Qa - Qb)*b3
+	reply := &Message4{Rb: s.Curve.PointScalarMul(qab, sess.x3)}
+	var err error
+	if reply.CR, reply.D7, err = s.proveEqualLogs(rand, 0x08, qab, sess.x3); err != nil {
+		return nil, sess.abort(err)
+	}
+
+	// Rab = Ra*b3
+	sess.finish(m.Pa, sess.pb, s.Curve.PointScalarMul(m.Ra, sess.x3))
+	return reply, nil
+}
+
+// ReceiveMessage4 checks SMP message 4 as the initiator and learns the
+// result of the run. Errors can result if the session does not expect the
+// message or if the message is invalid.
+func (sess *Session) ReceiveMessage4(m *Message4) error {
+	s := sess.smp
+	if sess.state != ExpectMessage4 {
+		return sess.unexpected()
+	}
+	if m == nil || !s.arePointsValid(m.Rb) {
+		return sess.abort(ErrInvalidMessage)
+	}
+	qab := s.Curve.SubPoints(sess.qa, sess.qb)
+	if !s.verifyEqualLogs(0x08, sess.g3o, qab, m.Rb, m.CR, m.D7) {
+		return sess.abort(ErrInvalidMessage)
+	}
+
+	// Rab = Rb*a3
+	sess.finish(sess.pa, sess.pb, s.Curve.PointScalarMul(m.Rb, sess.x3))
+	return nil
+}
@@ -0,0 +1,284 @@
+package smp
+
+import (
+	"crypto/rand"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/testHelpers"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SMPSuite struct{}
+
+var _ = Suite(&SMPSuite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	smp       = &SMP{Curve: testCurve}
+	secret    = []byte("the name of our first cat")
+	question  = []byte("what was the name of our first cat?")
+)
+
+// run plays a whole run between the two sessions, sending every message
+// through its encoding
+func run(c *C, alice, bob *Session, aliceSecret, bobSecret []byte) {
+	m1, err := alice.Start(rand.Reader, aliceSecret, question)
+	c.Assert(err, IsNil)
+	m1, err = smp.DecodeMessage1(m1.Encode())
+	c.Assert(err, IsNil)
+	c.Assert(m1.Question, DeepEquals, question)
+
+	m2, err := bob.ReceiveMessage1(rand.Reader, bobSecret, m1)
+	c.Assert(err, IsNil)
+	m2, err = smp.DecodeMessage2(m2.Encode())
+	c.Assert(err, IsNil)
+
+	m3, err := alice.ReceiveMessage2(rand.Reader, m2)
+	c.Assert(err, IsNil)
+	m3, err = smp.DecodeMessage3(m3.Encode())
+	c.Assert(err, IsNil)
+
+	m4, err := bob.ReceiveMessage3(rand.Reader, m3)
+	c.Assert(err, IsNil)
+	m4, err = smp.DecodeMessage4(m4.Encode())
+	c.Assert(err, IsNil)
+
+	c.Assert(alice.ReceiveMessage4(m4), IsNil)
+}
+
+func (s *SMPSuite) Test_MatchingSecretsSucceed(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+
+	run(c, alice, bob, secret, secret)
+
+	c.Assert(alice.Outcome(), Equals, Succeeded)
+	c.Assert(bob.Outcome(), Equals, Succeeded)
+	c.Assert(alice.State(), Equals, ExpectMessage1)
+	c.Assert(bob.State(), Equals, ExpectMessage1)
+}
+
+func (s *SMPSuite) Test_DifferentSecretsFail(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+
+	run(c, alice, bob, secret, []byte("the name of our second cat"))
+
+	c.Assert(alice.Outcome(), Equals, Failed)
+	c.Assert(bob.Outcome(), Equals, Failed)
+}
+
+func (s *SMPSuite) Test_SessionsCanRunAgain(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+
+	run(c, alice, bob, secret, []byte("wrong"))
+	c.Assert(alice.Outcome(), Equals, Failed)
+
+	// the responder of one run can start the next
+	run(c, bob, alice, secret, secret)
+	c.Assert(alice.Outcome(), Equals, Succeeded)
+	c.Assert(bob.Outcome(), Equals, Succeeded)
+}
+
+func (s *SMPSuite) Test_StatesFollowTheProtocol(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+	c.Assert(alice.State(), Equals, ExpectMessage1)
+	c.Assert(alice.Outcome(), Equals, InProgress)
+
+	m1, err := alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+	c.Assert(alice.State(), Equals, ExpectMessage2)
+
+	m2, err := bob.ReceiveMessage1(rand.Reader, secret, m1)
+	c.Assert(err, IsNil)
+	c.Assert(bob.State(), Equals, ExpectMessage3)
+	c.Assert(bob.Outcome(), Equals, InProgress)
+
+	_, err = alice.ReceiveMessage2(rand.Reader, m2)
+	c.Assert(err, IsNil)
+	c.Assert(alice.State(), Equals, ExpectMessage4)
+	c.Assert(alice.Outcome(), Equals, InProgress)
+}
+
+func (s *SMPSuite) Test_UnexpectedMessagesAbort(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+	m1, err := alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+	m2, err := bob.ReceiveMessage1(rand.Reader, secret, m1)
+	c.Assert(err, IsNil)
+
+	_, err = bob.ReceiveMessage1(rand.Reader, secret, m1)
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+	c.Assert(bob.State(), Equals, ExpectMessage1)
+	c.Assert(bob.Outcome(), Equals, Aborted)
+
+	_, err = bob.ReceiveMessage3(rand.Reader, &Message3{})
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+
+	c.Assert(alice.ReceiveMessage4(&Message4{}), Equals, ErrUnexpectedMessage)
+	c.Assert(alice.State(), Equals, ExpectMessage1)
+
+	_, err = alice.ReceiveMessage2(rand.Reader, m2)
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+}
+
+func (s *SMPSuite) Test_StrayMessagesAfterARunAreIgnored(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+	run(c, alice, bob, secret, secret)
+
+	_, err := alice.ReceiveMessage2(rand.Reader, &Message2{})
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+	c.Assert(alice.ReceiveMessage4(&Message4{}), Equals, ErrUnexpectedMessage)
+	_, err = bob.ReceiveMessage3(rand.Reader, &Message3{})
+	c.Assert(err, Equals, ErrUnexpectedMessage)
+
+	c.Assert(alice.Outcome(), Equals, Succeeded)
+	c.Assert(bob.Outcome(), Equals, Succeeded)
+	c.Assert(alice.State(), Equals, ExpectMessage1)
+	c.Assert(bob.State(), Equals, ExpectMessage1)
+}
+
+func (s *SMPSuite) Test_StartAbortsARunInProgress(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+	old, err := alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+	m2, err := bob.ReceiveMessage1(rand.Reader, secret, old)
+	c.Assert(err, IsNil)
+
+	_, err = alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+	c.Assert(alice.State(), Equals, ExpectMessage2)
+
+	// the answer to the old message 1 does not match the new run
+	_, err = alice.ReceiveMessage2(rand.Reader, m2)
+	c.Assert(err, Equals, ErrInvalidMessage)
+	c.Assert(alice.Outcome(), Equals, Aborted)
+}
+
+func (s *SMPSuite) Test_Abort(c *C) {
+	alice := smp.NewSession()
+	_, err := alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+
+	alice.Abort()
+
+	c.Assert(alice.State(), Equals, ExpectMessage1)
+	c.Assert(alice.Outcome(), Equals, Aborted)
+}
+
+func (s *SMPSuite) Test_InvalidMessage1Aborts(c *C) {
+	alice := smp.NewSession()
+	m1, err := alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+	delta := testHelpers.MustCreateRandScalar()
+
+	tampered := []*Message1{
+		{G2a: m1.G2a, C2: testCurve.AddScalars(m1.C2, delta), D2: m1.D2, G3a: m1.G3a, C3: m1.C3, D3: m1.D3},
+		{G2a: m1.G2a, C2: m1.C2, D2: m1.D2, G3a: m1.G3a, C3: m1.C3, D3: testCurve.AddScalars(m1.D3, delta)},
+		{G2a: m1.G3a, C2: m1.C2, D2: m1.D2, G3a: m1.G3a, C3: m1.C3, D3: m1.D3},
+		{G2a: testCurve.Identity(), C2: m1.C2, D2: m1.D2, G3a: m1.G3a, C3: m1.C3, D3: m1.D3},
+		{G2a: m1.G2a, D2: m1.D2, G3a: m1.G3a, C3: m1.C3, D3: m1.D3},
+		nil,
+	}
+	for _, m := range tampered {
+		bob := smp.NewSession()
+		_, err := bob.ReceiveMessage1(rand.Reader, secret, m)
+		c.Assert(err, Equals, ErrInvalidMessage)
+		c.Assert(bob.Outcome(), Equals, Aborted)
+	}
+}
+
+func (s *SMPSuite) Test_InvalidMessage2Aborts(c *C) {
+	delta := testHelpers.MustCreateRandScalar()
+	tamper := []func(m *Message2){
+		func(m *Message2) { m.C2 = testCurve.AddScalars(m.C2, delta) },
+		func(m *Message2) { m.D3 = testCurve.AddScalars(m.D3, delta) },
+		func(m *Message2) { m.CP = testCurve.AddScalars(m.CP, delta) },
+		func(m *Message2) { m.D6 = testCurve.AddScalars(m.D6, delta) },
+		func(m *Message2) { m.Pb = testCurve.G() },
+		func(m *Message2) { m.Qb = testCurve.Identity() },
+	}
+	for _, t := range tamper {
+		alice, bob := smp.NewSession(), smp.NewSession()
+		m1, err := alice.Start(rand.Reader, secret, nil)
+		c.Assert(err, IsNil)
+		m2, err := bob.ReceiveMessage1(rand.Reader, secret, m1)
+		c.Assert(err, IsNil)
+
+		t(m2)
+		_, err = alice.ReceiveMessage2(rand.Reader, m2)
+		c.Assert(err, Equals, ErrInvalidMessage)
+		c.Assert(alice.Outcome(), Equals, Aborted)
+		c.Assert(alice.State(), Equals, ExpectMessage1)
+	}
+}
+
+func (s *SMPSuite) Test_InvalidMessage3Aborts(c *C) {
+	delta := testHelpers.MustCreateRandScalar()
+	tamper := []func(m *Message3){
+		func(m *Message3) { m.CP = testCurve.AddScalars(m.CP, delta) },
+		func(m *Message3) { m.D5 = testCurve.AddScalars(m.D5, delta) },
+		func(m *Message3) { m.CR = testCurve.AddScalars(m.CR, delta) },
+		func(m *Message3) { m.D7 = testCurve.AddScalars(m.D7, delta) },
+		func(m *Message3) { m.Ra = testCurve.G() },
+		func(m *Message3) { m.Pa = testCurve.G() },
+	}
+	for _, t := range tamper {
+		alice, bob := smp.NewSession(), smp.NewSession()
+		m1, err := alice.Start(rand.Reader, secret, nil)
+		c.Assert(err, IsNil)
+		m2, err := bob.ReceiveMessage1(rand.Reader, secret, m1)
+		c.Assert(err, IsNil)
+		m3, err := alice.ReceiveMessage2(rand.Reader, m2)
+		c.Assert(err, IsNil)
+
+		t(m3)
+		_, err = bob.ReceiveMessage3(rand.Reader, m3)
+		c.Assert(err, Equals, ErrInvalidMessage)
+		c.Assert(bob.Outcome(), Equals, Aborted)
+	}
+}
+
+func (s *SMPSuite) Test_InvalidMessage4Aborts(c *C) {
+	delta := testHelpers.MustCreateRandScalar()
+	tamper := []func(m *Message4){
+		func(m *Message4) { m.CR = testCurve.AddScalars(m.CR, delta) },
+		func(m *Message4) { m.D7 = testCurve.AddScalars(m.D7, delta) },
+		// a forged Rb = Pa - Pb would make the secrets look equal
+		func(m *Message4) { m.Rb = testCurve.G() },
+		func(m *Message4) { m.Rb = nil },
+	}
+	for _, t := range tamper {
+		alice, bob := smp.NewSession(), smp.NewSession()
+		m1, err := alice.Start(rand.Reader, secret, nil)
+		c.Assert(err, IsNil)
+		m2, err := bob.ReceiveMessage1(rand.Reader, []byte("wrong"), m1)
+		c.Assert(err, IsNil)
+		m3, err := alice.ReceiveMessage2(rand.Reader, m2)
+		c.Assert(err, IsNil)
+		m4, err := bob.ReceiveMessage3(rand.Reader, m3)
+		c.Assert(err, IsNil)
+
+		t(m4)
+		c.Assert(alice.ReceiveMessage4(m4), Equals, ErrInvalidMessage)
+		c.Assert(alice.Outcome(), Equals, Aborted)
+	}
+}
+
+func (s *SMPSuite) Test_RandomFailuresAbort(c *C) {
+	alice, bob := smp.NewSession(), smp.NewSession()
+	fixed := testHelpers.FixedRandReader([]byte{0x00})
+
+	_, err := alice.Start(fixed, secret, nil)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+	c.Assert(alice.Outcome(), Equals, Aborted)
+	c.Assert(alice.State(), Equals, ExpectMessage1)
+
+	m1, err := alice.Start(rand.Reader, secret, nil)
+	c.Assert(err, IsNil)
+	_, err = bob.ReceiveMessage1(fixed, secret, m1)
+	c.Assert(err, ErrorMatches, "cannot source enough entropy")
+	c.Assert(bob.Outcome(), Equals, Aborted)
+}
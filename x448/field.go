@@ -0,0 +1,209 @@
+package x448
+
+// fieldElement is an element of GF(p), p = 2^448 - 2^224 - 1, held as 16
+// little-endian limbs of 28 bits. Limbs may exceed 28 bits slightly between
+// operations; every operation accepts limbs below 2^29.
+type fieldElement [16]uint32
+
+const (
+	limbBits  = 28
+	limbMask  = 1<<limbBits - 1
+	fieldSize = 56
+)
+
+// p in limbs: every limb is all ones except limb 8, because of the -2^224 term
+var fieldPrime = fieldElement{
+	limbMask, limbMask, limbMask, limbMask, limbMask, limbMask, limbMask, limbMask,
+	limbMask - 1, limbMask, limbMask, limbMask, limbMask, limbMask, limbMask, limbMask,
+}
+
+var (
+	feZero = fieldElement{}
+	feOne  = fieldElement{1}
+)
+
+// carry propagates the carries of 16 wide limbs, folding the carry out of
+// the top limb back in with 2^448 = 2^224 + 1 (mod p)
+func carry(c *[16]uint64) fieldElement {
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i < 15; i++ {
+			c[i+1] += c[i] >> limbBits
+			c[i] &= limbMask
+		}
+		t := c[15] >> limbBits
+		c[15] &= limbMask
+		c[0] += t
+		c[8] += t
+	}
+
+	var r fieldElement
+	for i := range r {
+		r[i] = uint32(c[i])
+	}
+	return r
+}
+
+func feAdd(a, b *fieldElement) fieldElement {
+	var c [16]uint64
+	for i := range c {
+		c[i] = uint64(a[i]) + uint64(b[i])
+	}
+	return carry(&c)
+}
+
+// feSub computes a - b as a + 4p - b, so no limb goes negative
+func feSub(a, b *fieldElement) fieldElement {
+	var c [16]uint64
+	for i := range c {
+		c[i] = uint64(a[i]) + 4*uint64(fieldPrime[i]) - uint64(b[i])
+	}
+	return carry(&c)
+}
+
+func feNeg(a *fieldElement) fieldElement {
+	return feSub(&feZero, a)
+}
+
+func feMul(a, b *fieldElement) fieldElement {
+	var c [31]uint64
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 16; j++ {
+			c[i+j] += uint64(a[i]) * uint64(b[j])
+		}
+	}
+
+	// 2^(28k) = 2^(28(k-16)) + 2^(28(k-8)) for k >= 16. Going down folds the
+	// limbs that land above 16 again before they are read.
+	for k := 30; k >= 16; k-- {
+		c[k-16] += c[k]
+		c[k-8] += c[k]
+	}
+
+	var low [16]uint64
+	copy(low[:], c[:16])
+	return carry(&low)
+}
+
+func feSquare(a *fieldElement) fieldElement {
+	return feMul(a, a)
+}
+
+func feMulSmall(a *fieldElement, k uint32) fieldElement {
+	var c [16]uint64
+	for i := range c {
+		c[i] = uint64(a[i]) * uint64(k)
+	}
+	return carry(&c)
+}
+
+// fePow computes a^e for a public exponent e, given in little-endian order
+func fePow(a *fieldElement, e []byte) fieldElement {
+	r := feOne
+	for i := len(e)*8 - 1; i >= 0; i-- {
+		r = feSquare(&r)
+		if e[i/8]>>uint(i%8)&1 == 1 {
+			r = feMul(&r, a)
+		}
+	}
+	return r
+}
+
+// p - 2 in little-endian order, the exponent used to invert
+var primeMinusTwo = []byte{
+	0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+}
+
+// (p + 1) / 4 in little-endian order, the exponent used to take square roots
+var primePlusOneOverFour = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0xc0, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x3f,
+}
+
+func feInvert(a *fieldElement) fieldElement {
+	return fePow(a, primeMinusTwo)
+}
+
+// reduce returns the canonical form of a, with every limb below 2^28 and the
+// value below p
+func (a *fieldElement) reduce() fieldElement {
+	var c [16]uint64
+	for i := range c {
+		c[i] = uint64(a[i])
+	}
+	r := carry(&c)
+
+	// r - p, which borrows out of the top if r < p
+	var s int64
+	for i := range r {
+		s += int64(r[i]) - int64(fieldPrime[i])
+		r[i] = uint32(s) & limbMask
+		s >>= limbBits
+	}
+
+	// add p back if it borrowed; the carry out of the top is dropped
+	mask := uint32(s)
+	var u uint64
+	for i := range r {
+		u += uint64(r[i]) + uint64(fieldPrime[i]&mask)
+		r[i] = uint32(u) & limbMask
+		u >>= limbBits
+	}
+	return r
+}
+
+// encode returns the canonical little-endian encoding of a in 56 bytes
+func (a *fieldElement) encode() []byte {
+	r := a.reduce()
+	b := make([]byte, fieldSize)
+	for i := 0; i < 8; i++ {
+		v := uint64(r[2*i]) | uint64(r[2*i+1])<<limbBits
+		for j := 0; j < 7; j++ {
+			b[7*i+j] = byte(v >> uint(8*j))
+		}
+	}
+	return b
+}
+
+// decodeField decodes 56 little-endian bytes, and reports whether they were
+// the canonical encoding of a field element
+func decodeField(b []byte) (fieldElement, bool) {
+	var a fieldElement
+	for i := 0; i < 8; i++ {
+		var v uint64
+		for j := 0; j < 7; j++ {
+			v |= uint64(b[7*i+j]) << uint(8*j)
+		}
+		a[2*i] = uint32(v) & limbMask
+		a[2*i+1] = uint32(v>>limbBits) & limbMask
+	}
+	return a, string(a.encode()) == string(b)
+}
+
+func feEqual(a, b *fieldElement) bool {
+	return string(a.encode()) == string(b.encode())
+}
+
+func (a *fieldElement) isNegative() byte {
+	return a.encode()[0] & 1
+}
+
+// feSelect returns a if cond is 1 and b if cond is 0, in constant time
+func feSelect(cond uint32, a, b *fieldElement) fieldElement {
+	mask := -cond
+	var r fieldElement
+	for i := range r {
+		r[i] = b[i] ^ (mask & (a[i] ^ b[i]))
+	}
+	return r
+}
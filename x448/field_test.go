@@ -0,0 +1,85 @@
+package x448
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	. "gopkg.in/check.v1"
+)
+
+var fieldPrimeInt, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+
+func littleEndian(b []byte) *big.Int {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(r)
+}
+
+func toLittleEndian(n *big.Int) []byte {
+	b := make([]byte, fieldSize)
+	be := n.Bytes()
+	for i := range be {
+		b[i] = be[len(be)-1-i]
+	}
+	return b
+}
+
+func randomField() (fieldElement, *big.Int) {
+	n, _ := rand.Int(rand.Reader, fieldPrimeInt)
+	fe, ok := decodeField(toLittleEndian(n))
+	if !ok {
+		panic("cannot decode a random field element")
+	}
+	return fe, n
+}
+
+func (s *X448Suite) Test_FieldArithmetic(c *C) {
+	p := fieldPrimeInt
+	for i := 0; i < 200; i++ {
+		a, an := randomField()
+		b, bn := randomField()
+
+		sum := feAdd(&a, &b)
+		c.Assert(littleEndian(sum.encode()), DeepEquals, new(big.Int).Mod(new(big.Int).Add(an, bn), p))
+
+		diff := feSub(&a, &b)
+		c.Assert(littleEndian(diff.encode()), DeepEquals, new(big.Int).Mod(new(big.Int).Sub(an, bn), p))
+
+		prod := feMul(&a, &b)
+		c.Assert(littleEndian(prod.encode()), DeepEquals, new(big.Int).Mod(new(big.Int).Mul(an, bn), p))
+
+		small := feMulSmall(&a, edwardsD)
+		c.Assert(littleEndian(small.encode()), DeepEquals, new(big.Int).Mod(new(big.Int).Mul(an, big.NewInt(edwardsD)), p))
+	}
+
+	a, an := randomField()
+	inv := feInvert(&a)
+	c.Assert(littleEndian(inv.encode()), DeepEquals, new(big.Int).ModInverse(an, p))
+}
+
+func (s *X448Suite) Test_FieldLimbsStayBounded(c *C) {
+	// p - 1 has the largest limbs, so its products have the largest sums
+	max, _ := decodeField(toLittleEndian(new(big.Int).Sub(fieldPrimeInt, big.NewInt(1))))
+	r := max
+	for i := 0; i < 20; i++ {
+		r = feMul(&r, &max)
+		r = feAdd(&r, &max)
+		r = feSub(&r, &max)
+		for _, limb := range r {
+			c.Assert(limb < 1<<29, Equals, true)
+		}
+	}
+	one := feMul(&max, &max)
+	c.Assert(feEqual(&one, &feOne), Equals, true)
+}
+
+func (s *X448Suite) Test_DecodeField(c *C) {
+	_, ok := decodeField(toLittleEndian(fieldPrimeInt))
+	c.Assert(ok, Equals, false)
+
+	fe, ok := decodeField(toLittleEndian(big.NewInt(5)))
+	c.Assert(ok, Equals, true)
+	c.Assert(fe.encode(), DeepEquals, toLittleEndian(big.NewInt(5)))
+}
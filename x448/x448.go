@@ -0,0 +1,238 @@
+// Package x448 implements the X448 function of RFC 7748 and converts between
+// its u-coordinates and the points of the Edwards curve of Ed448.
+package x448
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/twtiger/crypto/curve"
+)
+
+// Size is the size of X448 scalars and u-coordinates
+const Size = fieldSize
+
+// montgomeryA and montgomeryA24 are A and (A - 2) / 4 for Curve448,
+// v^2 = u^3 + A*u^2 + u
+const (
+	montgomeryA   = 156326
+	montgomeryA24 = 39081
+)
+
+// edwardsD is the negation of d of the Edwards curve of Ed448,
+// x^2 + y^2 = 1 + d*x^2*y^2
+const edwardsD = 39081
+
+// Basepoint is the u-coordinate of the base point of Curve448, 5
+var Basepoint = append([]byte{5}, make([]byte, Size-1)...)
+
+// ErrLowOrderPoint is returned by X448 when the result is zero, which only
+// happens for u-coordinates of points of small order
+var ErrLowOrderPoint = errors.New("low order point")
+
+// quarter is the inverse of 4 modulo q in little-endian order
+var quarter = []byte{
+	0x3d, 0x11, 0xd6, 0xaa, 0xa4, 0x30, 0xde, 0x48,
+	0xd5, 0x63, 0x71, 0xa3, 0x9c, 0x30, 0x5b, 0x08,
+	0xa4, 0x8d, 0xb5, 0x6b, 0xd2, 0xb6, 0x13, 0x71,
+	0xfa, 0x88, 0x32, 0xdf, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x0f,
+}
+
+// X448 computes the function of RFC 7748, section 5: the u-coordinate of k*P,
+// where P is a point with the given u-coordinate and k is the clamped scalar.
+// The u-coordinate does not need to be canonical. The Montgomery ladder takes
+// the same steps for every scalar. An error results if an input has the
+// wrong size or the result is zero.
+func X448(scalar, u []byte) ([]byte, error) {
+	if len(scalar) != Size || len(u) != Size {
+		return nil, errors.New("invalid X448 input length")
+	}
+	k := append([]byte{}, scalar...)
+	k[0] &= 0xfc
+	k[Size-1] |= 0x80
+
+	x1, _ := decodeField(u)
+	r := montgomeryLadder(k, &x1)
+	if feEqual(&r, &feZero) {
+		return nil, ErrLowOrderPoint
+	}
+	return r.encode(), nil
+}
+
+// montgomeryLadder computes the u-coordinate of k*P from the u-coordinate x1
+// of P, with the ladder of RFC 7748, section 5
+func montgomeryLadder(k []byte, x1 *fieldElement) fieldElement {
+	x2, z2, x3, z3 := feOne, feZero, *x1, feOne
+	swap := uint32(0)
+	for t := len(k)*8 - 1; t >= 0; t-- {
+		kt := uint32(k[t/8]>>uint(t%8)) & 1
+		swap ^= kt
+		x2, x3 = feSelect(swap, &x3, &x2), feSelect(swap, &x2, &x3)
+		z2, z3 = feSelect(swap, &z3, &z2), feSelect(swap, &z2, &z3)
+		swap = kt
+
+		a := feAdd(&x2, &z2)
+		aa := feSquare(&a)
+		b := feSub(&x2, &z2)
+		bb := feSquare(&b)
+		e := feSub(&aa, &bb)
+		c := feAdd(&x3, &z3)
+		d := feSub(&x3, &z3)
+		da := feMul(&d, &a)
+		cb := feMul(&c, &b)
+
+		sum := feAdd(&da, &cb)
+		diff := feSub(&da, &cb)
+		diff = feSquare(&diff)
+		x3 = feSquare(&sum)
+		z3 = feMul(x1, &diff)
+		x2 = feMul(&aa, &bb)
+		a24e := feMulSmall(&e, montgomeryA24)
+		a24e = feAdd(&aa, &a24e)
+		z2 = feMul(&e, &a24e)
+	}
+	x2 = feSelect(swap, &x3, &x2)
+	z2 = feSelect(swap, &z3, &z2)
+
+	zInv := feInvert(&z2)
+	return feMul(&x2, &zInv)
+}
+
+// Converter converts between the u-coordinates of X448 and the points of a
+// curve whose EdDSA encoding is that of Ed448, such as curve.Ed448Gold.
+//
+// Curve448 is not birationally equivalent to the Edwards curve of Ed448 but
+// 4-isogenous to it. The map of RFC 7748, section 4.2, u = y^2/x^2, takes
+// the base point of Ed448 to the base point of X448 and k*P to k times the
+// image of P, so keys and shared secrets correspond on both sides: the Ed448
+// secret scalar k of a point P, pruned as RFC 8032 does, is an X448 private
+// key whose public key is the u-coordinate of P.
+type Converter struct {
+	Curve Curve
+}
+
+// Curve defines what curve functions are required to convert between points
+// and u-coordinates
+type Curve interface {
+	curve.BasicCurve
+	curve.PointCalculator
+	curve.IdentityPoint
+	curve.ScalarDecoder
+	curve.EdDSAEncoder
+}
+
+var (
+	errInvalidPoint      = errors.New("invalid point")
+	errInvalidCoordinate = errors.New("invalid u-coordinate")
+)
+
+// FromPoint returns the u-coordinate of the image of p on Curve448. With
+// x^2 = (y^2 - 1)/(d*y^2 - 1), u only depends on the y of the RFC 8032
+// encoding of p. An error results if p is the identity or the point of order
+// 2, whose x is zero.
+func (cv *Converter) FromPoint(p curve.Point) ([]byte, error) {
+	y, _ := decodeField(cv.Curve.EncodeEdDSA(p)[:fieldSize])
+	u, ok := montgomeryU(&y)
+	if !ok {
+		return nil, errInvalidPoint
+	}
+	return u.encode(), nil
+}
+
+// montgomeryU computes u = y^2 (d*y^2 - 1) / (y^2 - 1), which is y^2/x^2,
+// and whether x is not zero
+func montgomeryU(y *fieldElement) (fieldElement, bool) {
+	yy := feSquare(y)
+	yy1 := feSub(&yy, &feOne)
+	dyy := feMulSmall(&yy, edwardsD)
+	dyy = feNeg(&dyy)
+	dyy1 := feSub(&dyy, &feOne)
+	num := feMul(&yy, &dyy1)
+	denInv := feInvert(&yy1)
+	return feMul(&num, &denInv), !feEqual(&yy1, &feZero)
+}
+
+// ToPoint returns the point FromPoint maps to the u-coordinate. The
+// u-coordinate does not hold the sign of x, so it is given as sign, the top
+// bit of the RFC 8032 encoding of the point.
+//
+// The dual isogeny of RFC 7748, section 4.2, maps the image of P to 4*P, so
+// its result is multiplied by the inverse of 4 modulo q. An error results if
+// the u-coordinate is not of a point of the prime order subgroup.
+func (cv *Converter) ToPoint(u []byte, sign byte) (curve.Point, error) {
+	if len(u) != Size || sign > 1 {
+		return nil, errInvalidCoordinate
+	}
+	uu, _ := decodeField(u)
+
+	// v^2 = u^3 + A*u^2 + u
+	u2 := feSquare(&uu)
+	u3 := feMul(&u2, &uu)
+	au2 := feMulSmall(&u2, montgomeryA)
+	vv := feAdd(&u3, &au2)
+	vv = feAdd(&vv, &uu)
+	v := fePow(&vv, primePlusOneOverFour)
+	if check := feSquare(&v); !feEqual(&check, &vv) {
+		return nil, errInvalidCoordinate
+	}
+
+	// x = 4v(u^2 - 1) / (u^4 - 2u^2 + 4v^2 + 1)
+	// y = -(u^5 - 2u^3 - 4uv^2 + u) / (u^5 - 2u^2v^2 - 2u^3 - 2v^2 + u)
+	u4 := feSquare(&u2)
+	u5 := feMul(&u4, &uu)
+	twoU2 := feMulSmall(&u2, 2)
+	twoU3 := feMulSmall(&u3, 2)
+	twoVV := feMulSmall(&vv, 2)
+	fourVV := feMulSmall(&vv, 4)
+
+	u2m1 := feSub(&u2, &feOne)
+	xNum := feMul(&v, &u2m1)
+	xNum = feMulSmall(&xNum, 4)
+	xDen := feSub(&u4, &twoU2)
+	xDen = feAdd(&xDen, &fourVV)
+	xDen = feAdd(&xDen, &feOne)
+
+	fourUVV := feMul(&uu, &fourVV)
+	yNum := feSub(&u5, &twoU3)
+	yNum = feSub(&yNum, &fourUVV)
+	yNum = feAdd(&yNum, &uu)
+	yNum = feNeg(&yNum)
+	twoU2VV := feMul(&twoU2, &vv)
+	yDen := feSub(&u5, &twoU2VV)
+	yDen = feSub(&yDen, &twoU3)
+	yDen = feSub(&yDen, &twoVV)
+	yDen = feAdd(&yDen, &uu)
+
+	if feEqual(&xDen, &feZero) || feEqual(&yDen, &feZero) {
+		return nil, errInvalidCoordinate
+	}
+	xDenInv := feInvert(&xDen)
+	yDenInv := feInvert(&yDen)
+	x := feMul(&xNum, &xDenInv)
+	y := feMul(&yNum, &yDenInv)
+
+	fourP, err := cv.Curve.DecodeEdDSA(append(y.encode(), x.isNegative()<<7))
+	if err != nil {
+		return nil, errInvalidCoordinate
+	}
+	inverseOfFour, err := cv.Curve.DecodeScalar(quarter)
+	if err != nil {
+		panic("programmer error: cannot decode the inverse of 4")
+	}
+	p := cv.Curve.PointScalarMul(fourP, inverseOfFour)
+
+	// the dual isogeny also maps points outside the subgroup into it, so
+	// check that the result maps back to u
+	back, err := cv.FromPoint(p)
+	if err != nil || !bytes.Equal(back, uu.encode()) {
+		return nil, errInvalidCoordinate
+	}
+
+	if cv.Curve.EncodeEdDSA(p)[fieldSize]>>7 != sign {
+		p = cv.Curve.SubPoints(cv.Curve.Identity(), p)
+	}
+	return p, nil
+}
@@ -0,0 +1,201 @@
+package x448
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/twtiger/crypto/curve"
+	"github.com/twtiger/crypto/ed448sig"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type X448Suite struct{}
+
+var _ = Suite(&X448Suite{})
+
+var (
+	testCurve = &curve.Ed448Gold{}
+	converter = &Converter{Curve: testCurve}
+	ed448     = &ed448sig.Ed448{Curve: testCurve}
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var longTests = flag.Bool("long", false, "run the RFC 7748 test that iterates X448 a million times")
+
+// test vectors from RFC 7748, section 5.2
+var x448Vectors = []struct {
+	scalar, u, out string
+}{
+	{
+		scalar: "3d262fddf9ec8e88495266fea19a34d28882acef045104d0d1aae121700a779c984c24f8cdd78fbff44943eba368f54b29259a4f1c600ad3",
+		u:      "06fce640fa3487bfda5f6cf2d5263f8aad88334cbd07437f020f08f9814dc031ddbdc38c19c6da2583fa5429db94ada18aa7a7fb4ef8a086",
+		out:    "ce3e4ff95a60dc6697da1db1d85e6afbdf79b50a2412d7546d5f239fe14fbaadeb445fc66a01b0779d98223961111e21766282f73dd96b6f",
+	},
+	{
+		scalar: "203d494428b8399352665ddca42f9de8fef600908e0d461cb021f8c538345dd77c3e4806e25f46d3315c44e0a5b4371282dd2c8d5be3095f",
+		u:      "0fbcc2f993cd56d3305b0b7d9e55d4c1a8fb5dbb52f8e9a1e9b6201b165d015894e56c4d3570bee52fe205e28a78b91cdfbde71ce8d157db",
+		out:    "884a02576239ff7a2f2f63b2db6a9ff37047ac13568e1e30fe63c4a7ad1b3ee3a5700df34321d62077e63633c575c1c954514e99da7c179d",
+	},
+}
+
+func (s *X448Suite) Test_X448Vectors(c *C) {
+	for _, v := range x448Vectors {
+		out, err := X448(decodeHex(v.scalar), decodeHex(v.u))
+		c.Assert(err, IsNil)
+		c.Assert(out, DeepEquals, decodeHex(v.out))
+	}
+}
+
+// iterateX448 runs the iterated test of RFC 7748, section 5.2: starting from
+// k = u = 5, k becomes X448(k, u) and u becomes the old k
+func iterateX448(c *C, n int) []byte {
+	k, u := Basepoint, Basepoint
+	for i := 0; i < n; i++ {
+		out, err := X448(k, u)
+		c.Assert(err, IsNil)
+		k, u = out, k
+	}
+	return k
+}
+
+func (s *X448Suite) Test_X448Iterations(c *C) {
+	c.Assert(iterateX448(c, 1), DeepEquals, decodeHex("3f482c8a9f19b01e6c46ee9711d9dc14fd4bf67af30765c2ae2b846a4d23a8cd0db897086239492caf350b51f833868b9bc2b3bca9cf4113"))
+	c.Assert(iterateX448(c, 1000), DeepEquals, decodeHex("aa3b4749d55b9daf1e5b00288826c467274ce3ebbdd5c17b975e09d4af6c67cf10d087202db88286e2b79fceea3ec353ef54faa26e219f38"))
+
+	if !*longTests {
+		c.Skip("-long is not set")
+	}
+	c.Assert(iterateX448(c, 1000000), DeepEquals, decodeHex("077f453681caca3693198420bbe515cae0002472519b3e67661a7e89cab94695c8f4bcd66e61b9b9c946da8d524de3d69bd9d9d66b997e37"))
+}
+
+// test vectors from RFC 7748, section 6.2
+func (s *X448Suite) Test_X448DiffieHellman(c *C) {
+	alicePriv := decodeHex("9a8f4925d1519f5775cf46b04b5800d4ee9ee8bae8bc5565d498c28dd9c9baf574a9419744897391006382a6f127ab1d9ac2d8c0a598726b")
+	bobPriv := decodeHex("1c306a7ac2a0e2e0990b294470cba339e6453772b075811d8fad0d1d6927c120bb5ee8972b0d3e21374c9c921b09d1b0366f10b65173992d")
+
+	alicePub, err := X448(alicePriv, Basepoint)
+	c.Assert(err, IsNil)
+	c.Assert(alicePub, DeepEquals, decodeHex("9b08f7cc31b7e3e67d22d5aea121074a273bd2b83de09c63faa73d2c22c5d9bbc836647241d953d40c5b12da88120d53177f80e532c41fa0"))
+	bobPub, err := X448(bobPriv, Basepoint)
+	c.Assert(err, IsNil)
+	c.Assert(bobPub, DeepEquals, decodeHex("3eb7a829b0cd20f5bcfc0b599b6feccf6da4627107bdb0d4f345b43027d8b972fc3e34fb4232a13ca706dcb57aec3dae07bdc1c67bf33609"))
+
+	aliceShared, err := X448(alicePriv, bobPub)
+	c.Assert(err, IsNil)
+	bobShared, err := X448(bobPriv, alicePub)
+	c.Assert(err, IsNil)
+	c.Assert(aliceShared, DeepEquals, decodeHex("07fff4181ac6cc95ec1c16a94a0f74d12da232ce40a77552281d282bb60c0b56fd2464c335543936521c24403085d59a449a5037514a879d"))
+	c.Assert(bobShared, DeepEquals, aliceShared)
+}
+
+func (s *X448Suite) Test_X448RejectsInvalidInputs(c *C) {
+	scalar := make([]byte, Size)
+
+	_, err := X448(scalar[1:], Basepoint)
+	c.Assert(err, ErrorMatches, "invalid X448 input length")
+	_, err = X448(scalar, Basepoint[1:])
+	c.Assert(err, ErrorMatches, "invalid X448 input length")
+
+	// u = 0 and u = 1 are the points of small order on Curve448
+	one := append([]byte{1}, make([]byte, Size-1)...)
+	for _, u := range [][]byte{make([]byte, Size), one} {
+		_, err = X448(scalar, u)
+		c.Assert(err, Equals, ErrLowOrderPoint)
+	}
+}
+
+func (s *X448Suite) Test_X448DoesNotModifyTheScalar(c *C) {
+	scalar := make([]byte, Size)
+	for i := range scalar {
+		scalar[i] = 0xff
+	}
+
+	_, err := X448(scalar, Basepoint)
+
+	c.Assert(err, IsNil)
+	c.Assert(scalar[0], Equals, byte(0xff))
+}
+
+func (s *X448Suite) Test_BasePointsConvert(c *C) {
+	u, err := converter.FromPoint(testCurve.G())
+	c.Assert(err, IsNil)
+	c.Assert(u, DeepEquals, Basepoint)
+
+	p, err := converter.ToPoint(Basepoint, 0)
+	c.Assert(err, IsNil)
+	c.Assert(testCurve.EqualPoints(p, testCurve.G()), Equals, true)
+}
+
+func (s *X448Suite) Test_Ed448KeysConvertToX448Keys(c *C) {
+	for i := 0; i < 4; i++ {
+		pub, priv, err := ed448.GenerateKey(rand.Reader)
+		c.Assert(err, IsNil)
+		p, err := testCurve.DecodeEdDSA(pub)
+		c.Assert(err, IsNil)
+
+		u, err := converter.FromPoint(p)
+		c.Assert(err, IsNil)
+		fromScalar, err := X448(priv.SecretScalar(), Basepoint)
+		c.Assert(err, IsNil)
+		c.Assert(u, DeepEquals, fromScalar)
+
+		back, err := converter.ToPoint(u, pub[ed448sig.PublicKeySize-1]>>7)
+		c.Assert(err, IsNil)
+		c.Assert(testCurve.EncodeEdDSA(back), DeepEquals, []byte(pub))
+	}
+}
+
+func (s *X448Suite) Test_ConvertedKeysAgreeWithX448Keys(c *C) {
+	edPub, edPriv, err := ed448.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	edPoint, err := testCurve.DecodeEdDSA(edPub)
+	c.Assert(err, IsNil)
+	xPriv := make([]byte, Size)
+	_, err = rand.Read(xPriv)
+	c.Assert(err, IsNil)
+	xPub, err := X448(xPriv, Basepoint)
+	c.Assert(err, IsNil)
+
+	edAsX448, err := converter.FromPoint(edPoint)
+	c.Assert(err, IsNil)
+	shared1, err := X448(edPriv.SecretScalar(), xPub)
+	c.Assert(err, IsNil)
+	shared2, err := X448(xPriv, edAsX448)
+	c.Assert(err, IsNil)
+	c.Assert(shared1, DeepEquals, shared2)
+
+	xAsPoint, err := converter.ToPoint(xPub, 0)
+	c.Assert(err, IsNil)
+	c.Assert(testCurve.EncodeEdDSA(xAsPoint)[ed448sig.PublicKeySize-1]>>7, Equals, byte(0))
+	again, err := converter.FromPoint(xAsPoint)
+	c.Assert(err, IsNil)
+	c.Assert(again, DeepEquals, xPub)
+}
+
+func (s *X448Suite) Test_ConversionRejectsInvalidInputs(c *C) {
+	_, err := converter.FromPoint(testCurve.Identity())
+	c.Assert(err, ErrorMatches, "invalid point")
+
+	// u = 0 is of small order, u = 6 is on the twist and u = 13 is outside the
+	// prime order subgroup
+	six := append([]byte{6}, make([]byte, Size-1)...)
+	thirteen := append([]byte{13}, make([]byte, Size-1)...)
+	for _, u := range [][]byte{make([]byte, Size), six, thirteen, Basepoint[1:]} {
+		_, err = converter.ToPoint(u, 0)
+		c.Assert(err, ErrorMatches, "invalid u-coordinate")
+	}
+	_, err = converter.ToPoint(Basepoint, 2)
+	c.Assert(err, ErrorMatches, "invalid u-coordinate")
+}